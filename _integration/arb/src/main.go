@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/pkg/xlate"
+)
+
+// Greeting is shown on startup
+const Greeting = "Hello, \"World\"!\nWelcome back."
+
+func main() {
+	fmt.Println(xlate.T(Greeting))
+	fmt.Println(xlate.TC("verb", "Open"))
+	fmt.Println(xlate.T("@{{.User}}'s files"))
+	fmt.Printf(xlate.TN("%d file deleted", "%d files deleted", 2)+"\n", 2)
+}
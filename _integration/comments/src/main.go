@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/_integration/comments/src/pkg"
+)
+
+// Open is a verb here
+const Open = "Open"
+
+func main() {
+	// TRANSLATORS: menu entry which opens a file
+	fmt.Println(Open)
+	fmt.Println(pkg.Closed) // TRANSLATORS: shown in the status bar
+	// an ordinary comment, not for translators
+	fmt.Println("Save")
+}
@@ -0,0 +1,6 @@
+package pkg
+
+const (
+	// Closed describes the state of a file, not an action
+	Closed = "Closed"
+)
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/_integration/constfold/src/pkg"
+)
+
+type message string
+
+const (
+	prefix         = "Unable to "
+	connect        = prefix + "connect"
+	typed  message = "typed string const"
+)
+
+func main() {
+	fmt.Println("Unable to connect " + "to the server")
+	fmt.Println(connect + (" to " + pkg.Server))
+	fmt.Println(pkg.Failed)
+	fmt.Println(string(typed))
+	fmt.Println(pkg.Repeated)
+}
@@ -0,0 +1,13 @@
+package pkg
+
+const Server = "the server"
+
+const (
+	Prefix = "operation "
+	Failed = Prefix + "failed"
+)
+
+const (
+	First    = "repeated const expression"
+	Repeated // implicitly repeats the expression above
+)
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/pkg/xlate"
+)
+
+const (
+	OpenVerb = "Open"
+	OpenAdj  = "Open"
+)
+
+func main() {
+	fmt.Println(xlate.TC("verb", OpenVerb))
+	fmt.Println(xlate.TC("adjective", OpenAdj))
+	fmt.Println(xlate.TC("menu", "Close"))
+	fmt.Println(xlate.T("Close"))
+}
@@ -1,12 +1,17 @@
 module github.com/mpictor/go-xtract/_integration
 
-go 1.12
+go 1.22.0
 
 replace github.com/mpictor/go-xtract => ../
 
 require (
-	github.com/jteeuwen/go-bindata v3.0.7+incompatible // indirect
 	github.com/mpictor/go-xtract v0.0.0-00010101000000-000000000000
 	github.com/stretchr/testify v1.7.0
 	gopkg.in/yaml.v2 v2.4.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,4 @@
+package auth
+
+// ErrDenied is shown when signing in fails
+const ErrDenied = "Access denied"
@@ -0,0 +1,4 @@
+package billing
+
+//xtract:id billing.denied
+const ErrDenied = "Payment declined"
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/_integration/keys/src/auth"
+	"github.com/mpictor/go-xtract/_integration/keys/src/billing"
+	"github.com/mpictor/go-xtract/pkg/xlate"
+)
+
+func main() {
+	fmt.Println(xlate.T(auth.ErrDenied))
+	fmt.Println(xlate.T(billing.ErrDenied))
+	//xtract:id greeting
+	fmt.Println(xlate.T("Hello!"))
+}
@@ -4,15 +4,19 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/xlate"
 )
 
 type spec struct {
@@ -101,12 +105,76 @@ func (r *testRunner) runCommand() string {
 	return output.String()
 }
 
+// verifyOutput compares the output line by line; xtract's output order is deterministic
 func (r *testRunner) verifyOutput(output string) {
 	expected := strings.Split(r.testSpec.Output, "\n")
 	actual := strings.Split(output, "\n")
 
-	sort.Strings(expected)
-	sort.Strings(actual)
-
 	assert.Equal(r.t, expected, actual)
 }
+
+// TestImportXLIFF imports a translated XLIFF document into an asset which does not exist yet, and loads the
+// assets with xlate. Golden tests cannot, as they only compare output.
+func TestImportXLIFF(t *testing.T) {
+	dir := t.TempDir()
+	xtract := func(args ...string) error {
+		cmd := exec.Command("xtract", args...)
+		cmd.Dir = "xliff"
+		out, err := cmd.CombinedOutput()
+		t.Logf("xtract %s:\n%s", strings.Join(args, " "), out)
+		return err
+	}
+	xlf := filepath.Join(dir, "fr.xlf")
+	require.NoError(t, xtract("-xliff", "2.0", "-lang", "fr", "-o", xlf, "src/main.go"))
+	doc, err := ioutil.ReadFile(xlf)
+	require.NoError(t, err)
+	// a translator finishes one segment, and starts another
+	doc = regexp.MustCompile(`state="initial">(\s*<source>Open</source>)`).
+		ReplaceAll(doc, []byte(`state="final">$1<target>Ouvrir</target>`))
+	doc = regexp.MustCompile(`<source>Hello, &lt;World&gt;!</source>`).
+		ReplaceAll(doc, []byte(`$0<target>Bonjour</target>`))
+	require.NoError(t, ioutil.WriteFile(xlf, doc, 0644))
+
+	asset := filepath.Join(dir, "fr.json")
+	assert.Error(t, xtract("-import-xliff", xlf, "-assets", dir), "a new asset needs -lang-name")
+	_, err = os.Stat(asset)
+	assert.True(t, os.IsNotExist(err), "no asset written without a name")
+
+	require.NoError(t, xtract("-import-xliff", xlf, "-assets", dir, "-lang-name", "Français"))
+	en := `{"AA_NativeLangName": "English", "Greeting": "Hello, <World>!", "verb_Open": "Open",
+		"@verb_Open": {"context": "verb"}}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "en.json"), []byte(en), 0644))
+	bd := xlate.Bindata{}
+	for _, name := range []string{"en.json", "fr.json"} {
+		path := filepath.Join(dir, name)
+		bd[name] = func() ([]byte, error) { return ioutil.ReadFile(path) }
+	}
+	b, err := xlate.NewBundle("English", bd)
+	require.NoError(t, err)
+	fr, err := b.Localizer("Français")
+	require.NoError(t, err)
+	assert.Equal(t, "Ouvrir", fr.TC("verb", "Open"))
+
+	// only translated segments are taken to be of the current text
+	c := readCatalog(t, asset)
+	for _, k := range []string{"Greeting", "verb_Open"} {
+		c.Meta[k] = &catalog.Meta{Source: "0123456789abcdef"}
+	}
+	f, err := os.Create(asset)
+	require.NoError(t, err)
+	require.NoError(t, c.Write(f))
+	require.NoError(t, f.Close())
+	require.NoError(t, xtract("-import-xliff", xlf, "-assets", dir))
+	c = readCatalog(t, asset)
+	assert.Equal(t, "Bonjour", c.Messages["Greeting"])
+	assert.Equal(t, &catalog.Meta{Source: "0123456789abcdef"}, c.Meta["Greeting"], "segment in initial state")
+	assert.Nil(t, c.Meta["verb_Open"], "final segment")
+}
+
+func readCatalog(t *testing.T, fname string) *catalog.Catalog {
+	data, err := ioutil.ReadFile(fname)
+	require.NoError(t, err)
+	c, err := catalog.Read(data)
+	require.NoError(t, err)
+	return c
+}
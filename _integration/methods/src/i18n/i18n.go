@@ -0,0 +1,17 @@
+package i18n
+
+// Translator is implemented by Localizer
+type Translator interface {
+	T(msg string) string
+}
+
+type Localizer struct {
+	Lang string
+}
+
+func (l *Localizer) T(msg string) string { return msg }
+
+// Other has a method named T which is not a target
+type Other struct{}
+
+func (Other) T(msg string) string { return msg }
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/_integration/methods/src/i18n"
+)
+
+func main() {
+	loc := &i18n.Localizer{Lang: "en"}
+	fmt.Println(loc.T("method on pointer receiver"))
+	t := loc.T
+	fmt.Println(t("method value"))
+	fmt.Println((*i18n.Localizer).T(loc, "method expression"))
+
+	var tr i18n.Translator = loc
+	fmt.Println(tr.T("interface method"))
+
+	fmt.Println(i18n.Other{}.T("not a target"))
+}
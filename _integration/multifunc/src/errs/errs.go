@@ -0,0 +1,7 @@
+package errs
+
+import "fmt"
+
+func New(code int, msg string) error {
+	return fmt.Errorf("%d: %s", code, msg)
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/_integration/multifunc/src/errs"
+)
+
+func main() {
+	fmt.Println("first argument of Println")
+	fmt.Printf("format %s", "not extracted")
+	fmt.Println(errs.New(404, "second argument of New"))
+}
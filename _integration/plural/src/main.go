@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/pkg/xlate"
+)
+
+const (
+	FileDeleted  = "%d file deleted"
+	FilesDeleted = "%d files deleted"
+)
+
+func main() {
+	for n := 0; n < 3; n++ {
+		fmt.Printf(xlate.TN(FileDeleted, FilesDeleted, n)+"\n", n)
+		fmt.Printf(xlate.TCN("search", "%d match", "%d matches", n)+"\n", n)
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import "fmt"
+
+const Greeting = "hello"
+
+type greeter struct{}
+
+func main() {
+	fmt.Println(Greeting)
+	(&greeter{}).greet()
+}
+
+func (*greeter) greet() {
+	fmt.Println(Greeting)
+	fmt.Println("only once")
+}
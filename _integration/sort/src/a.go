@@ -0,0 +1,8 @@
+package main
+
+import "github.com/mpictor/go-xtract/pkg/xlate"
+
+func a() {
+	xlate.T("first in a.go")
+	xlate.T("second in a.go")
+}
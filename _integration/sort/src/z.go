@@ -0,0 +1,9 @@
+package main
+
+import "github.com/mpictor/go-xtract/pkg/xlate"
+
+func main() {
+	xlate.T("first in z.go")
+	xlate.T("Second in z.go")
+	a()
+}
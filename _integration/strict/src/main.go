@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const greeting = "hello"
+
+func main() {
+	name := os.Args[0]
+	fmt.Println(greeting)
+	fmt.Println(name)
+	fmt.Println(fmt.Sprintf("%s!", greeting))
+}
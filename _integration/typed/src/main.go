@@ -0,0 +1,15 @@
+package main
+
+import (
+	. "github.com/mpictor/go-xtract/_integration/typed/src/pkg"
+)
+
+var fnVar = Fn
+
+func main() {
+	Fn("dot-imported function")
+	f := Fn
+	f("function value")
+	fnVar("package-level function var")
+	Greet()
+}
@@ -0,0 +1,16 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+)
+
+const greeting = "called from within the package"
+
+func Fn(s string) {
+	fmt.Fprintf(os.Stderr, "Fn(%q)\n", s)
+}
+
+func Greet() {
+	Fn(greeting)
+}
@@ -0,0 +1,16 @@
+//go:build ignore
+
+// references symbols which do not exist; excluded from the build so that the
+// rest of the integration tests still compile
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/_integration/unresolved/src/pkg"
+)
+
+func main() {
+	fmt.Println(pkg.Present)
+	fmt.Println(pkg.Missing)
+}
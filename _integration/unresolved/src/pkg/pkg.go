@@ -0,0 +1,3 @@
+package pkg
+
+const Present = "this constant exists"
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mpictor/go-xtract/pkg/xlate"
+)
+
+// Greeting is shown on startup
+const Greeting = "Hello, <World>!"
+
+func main() {
+	fmt.Println(xlate.T(Greeting))
+	fmt.Println(xlate.TC("verb", "Open"))
+	fmt.Printf(xlate.TN("%d file deleted", "%d files deleted", 2)+"\n", 2)
+}
@@ -0,0 +1,12 @@
+// Command xtractvet checks the use of translation functions; see package
+// github.com/mpictor/go-xtract/pkg/analyzer. It runs alone, as
+// 'xtractvet ./...', or within go vet, as 'go vet -vettool=$(which xtractvet) ./...'.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/mpictor/go-xtract/pkg/analyzer"
+)
+
+func main() { singlechecker.Main(analyzer.Analyzer) }
@@ -1,11 +1,20 @@
 module github.com/mpictor/go-xtract
 
-go 1.12
+go 1.22.0
 
 require (
-	github.com/MichaelTJones/walk v0.0.0-20161122175330-4748e29d5718 // indirect
-	github.com/mgutz/str v1.2.0 // indirect
 	github.com/pkg/errors v0.8.1
 	github.com/stretchr/testify v1.4.0
+	golang.org/x/tools v0.28.0
 	gopkg.in/godo.v2 v2.0.9
 )
+
+require (
+	github.com/MichaelTJones/walk v0.0.0-20161122175330-4748e29d5718 // indirect
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/mgutz/str v1.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
@@ -0,0 +1,212 @@
+// Package analyzer checks the use of translation functions, as an analysis.Analyzer which runs in go vet (see
+// cmd/xtractvet), gopls, golangci-lint and other drivers. Calls are matched as by xtract -typed, and it reports:
+//
+//   - arguments to the target functions which are not constant strings, so cannot be extracted
+//   - consts and vars passed to them which are not exported, which xtract requires
+//   - strings passed directly to sinks, such as fmt.Println, which are shown to users untranslated
+//
+// The targets are given by the -func flag, as for xtract, and the sinks by -sinks.
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/mpictor/go-xtract/pkg/extractor"
+)
+
+const doc = `check the use of translation functions
+
+Reports arguments to the translation functions (by default those of package
+xlate) which xtract cannot extract, as they are not constant strings, consts
+and vars passed to them which are not exported, and strings passed directly
+to user-facing sinks such as fmt.Println without translation.`
+
+// Analyzer checks the use of translation functions
+var Analyzer = &analysis.Analyzer{
+	Name:     "xtract",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	funcs = extractor.NewTargetList(extractor.DefaultTargets...)
+	sinks = sinkList{"fmt.Print", "fmt.Println", "fmt.Printf"}
+)
+
+func init() {
+	Analyzer.Flags.Var(funcs, "func", "target func `path/to/pkg.Func[:ARGS]`, as for xtract; may be repeated")
+	Analyzer.Flags.Var(&sinks, "sinks", "comma-separated `funcs` whose string arguments are shown to users, "+
+		"as path/to/pkg.Func or (*path/to/pkg.Type).Method; empty for none")
+}
+
+// sinkList is a flag of comma-separated funcs
+type sinkList []string
+
+func (s *sinkList) String() string { return strings.Join(*s, ",") }
+
+// targets parses the funcs
+func (s sinkList) targets() ([]extractor.Target, error) {
+	targets := make([]extractor.Target, 0, len(s))
+	for _, spec := range s {
+		t, err := extractor.ParseTarget(spec)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+func (s *sinkList) Set(value string) error {
+	*s = nil
+	for _, spec := range strings.Split(value, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		if _, err := extractor.ParseTarget(spec); err != nil {
+			return err
+		}
+		*s = append(*s, spec)
+	}
+	return nil
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	funcTargets, err := funcs.Targets()
+	if err != nil {
+		return nil, fmt.Errorf("-func: %w", err)
+	}
+	sinkTargets, err := sinks.targets()
+	if err != nil {
+		return nil, fmt.Errorf("-sinks: %w", err)
+	}
+	targets, sinkCalls := matcher(pass, funcTargets), matcher(pass, sinkTargets)
+	if !targets.Found() && !sinkCalls.Found() {
+		return nil, nil
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if target, args := targets.Match(pass.TypesInfo, call); target != nil {
+			checkTarget(pass, call, target, args)
+		} else if sink, args := sinkCalls.Match(pass.TypesInfo, call); sink != nil {
+			checkSink(pass, call, args)
+		}
+	})
+	return nil, nil
+}
+
+// matcher returns a Matcher for targets, which finds calls to them from the package analyzed
+func matcher(pass *analysis.Pass, targets []extractor.Target) *extractor.Matcher {
+	m := extractor.NewMatcher(targets...)
+	seen := make(map[*types.Package]bool)
+	var add func(pkg *types.Package)
+	add = func(pkg *types.Package) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		m.AddPackage(pkg)
+		for _, imp := range pkg.Imports() {
+			add(imp)
+		}
+	}
+	add(pass.Pkg)
+	for m.AddAliases(pass.TypesInfo, pass.Files) {
+	}
+	return m
+}
+
+// checkTarget reports the arguments of a call to a target which xtract cannot extract or key
+func checkTarget(pass *analysis.Pass, call *ast.CallExpr, target *extractor.Target, args []ast.Expr) {
+	fn := types.ExprString(call.Fun)
+	for _, arg := range []struct {
+		pos  int
+		name string
+	}{{target.Msg, "message"}, {target.Ctx, "context"}, {target.Plural, "plural form"}} {
+		if arg.pos < 0 || arg.pos >= len(args) || call.Ellipsis.IsValid() {
+			continue
+		}
+		expr := args[arg.pos]
+		if !constString(pass, expr) {
+			pass.Reportf(expr.Pos(), "%s argument of %s cannot be extracted: %s", arg.name, fn,
+				extractor.NonConstReason(expr))
+			continue
+		}
+		if arg.pos != target.Msg {
+			continue
+		}
+		if obj := objectOf(pass, expr); obj != nil && !obj.Exported() {
+			pass.Reportf(expr.Pos(), "%s passed to %s is not exported, so cannot be extracted by xtract", obj.Name(), fn)
+		}
+	}
+}
+
+// checkSink reports strings passed to a sink without translation. Strings without letters outside fmt verbs, such
+// as "\n" or "%s: %d", need none.
+func checkSink(pass *analysis.Pass, call *ast.CallExpr, args []ast.Expr) {
+	for _, arg := range args {
+		tv := pass.TypesInfo.Types[arg]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			continue
+		}
+		s := constant.StringVal(tv.Value)
+		if strings.IndexFunc(verb.ReplaceAllString(s, ""), unicode.IsLetter) >= 0 {
+			pass.Reportf(arg.Pos(), "untranslated string %q passed to %s", s, types.ExprString(call.Fun))
+		}
+	}
+}
+
+// verb matches a fmt verb, such as %-5.2f or %[2]*d
+var verb = regexp.MustCompile(`%[-+# 0]*(\[\d+\])?(\d+|\*)?(\.(\[\d+\])?(\d+|\*)?)?(\[\d+\])?[\pL%]`)
+
+// constString reports whether expr is a constant string expression, or a package-level var which xtract can
+// resolve to one: one declared in another package, whose source is not checked here, or in this package and
+// initialized with a constant string expression
+func constString(pass *analysis.Pass, expr ast.Expr) bool {
+	if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.Value != nil {
+		return tv.Value.Kind() == constant.String
+	}
+	v, ok := objectOf(pass, expr).(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return false
+	}
+	if v.Pkg() != pass.Pkg {
+		return true
+	}
+	for _, init := range pass.TypesInfo.InitOrder {
+		if len(init.Lhs) == 1 && init.Lhs[0] == v {
+			tv := pass.TypesInfo.Types[init.Rhs]
+			return tv.Value != nil && tv.Value.Kind() == constant.String
+		}
+	}
+	return false
+}
+
+// objectOf returns the const or var which expr names, if any
+func objectOf(pass *analysis.Pass, expr ast.Expr) types.Object {
+	var obj types.Object
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		obj = pass.TypesInfo.Uses[e]
+	case *ast.SelectorExpr:
+		obj = pass.TypesInfo.Uses[e.Sel]
+	}
+	switch obj.(type) {
+	case *types.Const, *types.Var:
+		return obj
+	}
+	return nil
+}
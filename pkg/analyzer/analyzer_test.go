@@ -0,0 +1,27 @@
+package analyzer
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestFlags(t *testing.T) {
+	defer func(s sinkList) { sinks = s }(sinks)
+	require.NoError(t, Analyzer.Flags.Set("sinks", "fmt.Println, log.Print,"))
+	assert.Equal(t, "fmt.Println,log.Print", sinks.String())
+	assert.Error(t, Analyzer.Flags.Set("sinks", "nope"), "invalid -sinks")
+	assert.Error(t, Analyzer.Flags.Set("func", "nope"), "invalid -func")
+}
+
+func TestLog(t *testing.T) {
+	assert.NotEqual(t, io.Discard, log.Writer(), "the standard logger belongs to the driver")
+}
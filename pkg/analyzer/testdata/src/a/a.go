@@ -0,0 +1,44 @@
+package a
+
+import (
+	"fmt"
+	"strings"
+
+	"b"
+
+	"github.com/mpictor/go-xtract/pkg/xlate"
+)
+
+const (
+	Greeting = "Hello!"
+	farewell = "Goodbye!"
+)
+
+var (
+	Title   = "Title"
+	Derived = strings.ToUpper("title")
+)
+
+func f(name string, n int) {
+	fmt.Println(xlate.T(Greeting))
+	fmt.Println(xlate.T("literal"), xlate.T("con"+"cat"))
+	fmt.Println(xlate.T(b.Exported), xlate.T(b.Var), xlate.T(Title))
+	fmt.Println(xlate.TC("verb", "Open"))
+	fmt.Printf(xlate.TN("%d file", "%d files", n), n)
+
+	fmt.Println(xlate.T(name))                        // want `message argument of xlate.T cannot be extracted: not a const, or a var initialized with a constant string expression`
+	fmt.Println(xlate.T(fmt.Sprint(n)))               // want `message argument of xlate.T cannot be extracted: result of a function call is not constant`
+	fmt.Println(xlate.T(Derived))                     // want `message argument of xlate.T cannot be extracted`
+	fmt.Println(xlate.TC(name, "Open"))               // want `context argument of xlate.TC cannot be extracted`
+	fmt.Printf(xlate.TN("%d file", "%d "+name, n), n) // want `plural form argument of xlate.TN cannot be extracted: operand is not a constant string expression`
+	fmt.Println(xlate.T(farewell))                    // want `farewell passed to xlate.T is not exported, so cannot be extracted by xtract`
+
+	t := xlate.T
+	fmt.Println(t(name)) // want `message argument of t cannot be extracted`
+
+	fmt.Println("Hello, World!") // want `untranslated string "Hello, World!" passed to fmt.Println`
+	fmt.Printf("%s: %d\n", name, n)
+	fmt.Printf("%d items\n", n) // want `untranslated string "%d items\\n" passed to fmt.Printf`
+	fmt.Print(Greeting, "\n")   // want `untranslated string "Hello!" passed to fmt.Print`
+	fmt.Println(fmt.Sprintf("hi %s", name))
+}
@@ -0,0 +1,5 @@
+package b
+
+const Exported = "exported"
+
+var Var = "var"
@@ -0,0 +1,277 @@
+// Package catalog reads and writes the json language assets produced by cmd/xtract and consumed by xlate.
+//
+// An asset is a json object mapping keys (typically const/var names) to phrases. Keys beginning with '@' hold
+// metadata about the message with the same key minus the '@', in the style of Flutter's ARB files:
+//  {
+//    "HelloWorld": "Hello, World!",
+//    "@HelloWorld": {
+//      "context": "startup",
+//      "description": "greeting shown on startup",
+//      "references": ["pkg/translatable.go:12"]
+//    }
+//  }
+// Metadata is optional. Only the context is used when translating; see xlate.TC. A translation's metadata may
+// also record the Fingerprint of the primary language's message it was made from, as "source"; xtract -sync
+// records it, and xlate does not use translations whose source has since changed.
+//
+// The key "@@obsolete" holds messages which the program no longer uses, in the same form, kept for translators'
+// reference when the messages are changed back or reworded:
+//  {
+//    "HelloWorld": "Hallo, Welt!",
+//    "@@obsolete": {
+//      "Goodbye": "Tschüss!"
+//    }
+//  }
+//
+// Plural messages are objects mapping CLDR plural categories to phrases. The
+// primary language's asset holds the singular as "one" and the plural as
+// "other"; translations hold whichever categories their language uses:
+//  {
+//    "FilesDeleted": {
+//      "one": "%d file deleted",
+//      "other": "%d files deleted"
+//    }
+//  }
+package catalog
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/plural"
+)
+
+const (
+	// MetaPrefix marks keys holding metadata rather than messages
+	MetaPrefix = "@"
+	// ObsoleteKey is the key of the messages no longer used
+	ObsoleteKey = "@@obsolete"
+	// NameKey is the key of the name of the language, as its speakers write it. xlate.SetLanguage requires it in
+	// every asset.
+	NameKey = "AA_NativeLangName"
+)
+
+// Catalog is the content of a json language asset
+type Catalog struct {
+	// maps key to phrase
+	Messages map[string]string
+	// maps key to the forms of a plural message
+	Plurals map[string]Plural
+	// maps key to metadata about the message; stored under MetaPrefix + key
+	Meta map[string]*Meta
+	// messages no longer used, if any; stored under ObsoleteKey
+	Obsolete *Catalog
+}
+
+// Meta is metadata about a message
+type Meta struct {
+	// disambiguates messages whose text is the same in the primary language
+	Context string `json:"context,omitempty"`
+	// comments for translators, explaining the message
+	Description string `json:"description,omitempty"`
+	// places the message is used, as file:line
+	References []string `json:"references,omitempty"`
+	// in a translation, the Fingerprint of the message in the primary language when it was translated
+	Source string `json:"source,omitempty"`
+}
+
+// Plural maps CLDR plural categories to the forms of a plural message
+type Plural map[plural.Category]string
+
+// New returns an empty catalog
+func New() *Catalog {
+	return &Catalog{
+		Messages: make(map[string]string),
+		Plurals:  make(map[string]Plural),
+		Meta:     make(map[string]*Meta),
+	}
+}
+
+// Read parses a json language asset
+func Read(data []byte) (*Catalog, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	c := New()
+	for k, v := range raw {
+		if k == ObsoleteKey {
+			obsolete, err := Read(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			c.Obsolete = obsolete
+			continue
+		}
+		if strings.HasPrefix(k, MetaPrefix) {
+			meta := new(Meta)
+			if err := json.Unmarshal(v, meta); err != nil {
+				return nil, fmt.Errorf("metadata %s: %w", k, err)
+			}
+			c.Meta[strings.TrimPrefix(k, MetaPrefix)] = meta
+			continue
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(v), []byte("{")) {
+			forms := make(Plural)
+			if err := json.Unmarshal(v, &forms); err != nil {
+				return nil, fmt.Errorf("key %s: %w", k, err)
+			}
+			for cat := range forms {
+				if !cat.Valid() {
+					return nil, fmt.Errorf("key %s: unknown plural category %q", k, cat)
+				}
+			}
+			c.Plurals[k] = forms
+			continue
+		}
+		var phrase string
+		if err := json.Unmarshal(v, &phrase); err != nil {
+			return nil, fmt.Errorf("key %s: %w", k, err)
+		}
+		c.Messages[k] = phrase
+	}
+	return c, nil
+}
+
+// Keys returns the keys of all messages, plural or not, sorted
+func (c *Catalog) Keys() []string {
+	keys := make([]string, 0, len(c.Messages)+len(c.Plurals))
+	for k := range c.Messages {
+		keys = append(keys, k)
+	}
+	for k := range c.Plurals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Write writes the catalog as json with two-space indentation and no HTML escaping. Messages are sorted by key,
+// each followed by its metadata if any.
+func (c *Catalog) Write(w io.Writer) error {
+	return c.WriteKeys(w, c.Keys())
+}
+
+// WriteKeys writes the catalog as Write does, but with the messages in the order of keys. Messages whose keys are
+// not given are omitted. Obsolete messages, if any, follow all others.
+func (c *Catalog) WriteKeys(w io.Writer, keys []string) error {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	first := true
+	entry := func(k string, v interface{}) error {
+		if !first {
+			buf.WriteString(",")
+		}
+		first = false
+		buf.WriteString("\n  ")
+		if err := Encode(&buf, k, ""); err != nil {
+			return err
+		}
+		buf.WriteString(": ")
+		return Encode(&buf, v, "  ")
+	}
+	for _, k := range keys {
+		var v interface{} = c.Messages[k]
+		if forms, ok := c.Plurals[k]; ok {
+			v = forms
+		} else if _, ok := c.Messages[k]; !ok {
+			continue
+		}
+		if err := entry(k, v); err != nil {
+			return err
+		}
+		if meta := c.Meta[k]; meta != nil && !meta.empty() {
+			if err := entry(MetaPrefix+k, meta); err != nil {
+				return err
+			}
+		}
+	}
+	if c.Obsolete != nil && len(c.Obsolete.Keys()) > 0 {
+		var obsolete bytes.Buffer
+		if err := c.Obsolete.Write(&obsolete); err != nil {
+			return err
+		}
+		if err := entry(ObsoleteKey, json.RawMessage(obsolete.Bytes())); err != nil {
+			return err
+		}
+	}
+	if !first {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// MarshalJSON writes the forms in CLDR order, rather than sorted by name
+func (p Plural) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for _, cat := range plural.Categories {
+		form, ok := p[cat]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteString(",")
+		}
+		if err := Encode(&buf, string(cat), ""); err != nil {
+			return nil, err
+		}
+		buf.WriteString(":")
+		if err := Encode(&buf, form, ""); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func (m *Meta) empty() bool {
+	return m.Context == "" && m.Description == "" && len(m.References) == 0 && m.Source == ""
+}
+
+// Fingerprint identifies the text and context of the message k, or of both forms of a plural message. It is ""
+// if there is no message k.
+func (c *Catalog) Fingerprint(k string) string {
+	text, ok := c.Messages[k]
+	if forms, isPlural := c.Plurals[k]; isPlural {
+		text, ok = forms[plural.One]+"\x00"+forms[plural.Other], true
+	}
+	if !ok {
+		return ""
+	}
+	ctx := ""
+	if meta := c.Meta[k]; meta != nil {
+		ctx = meta.Context
+	}
+	sum := sha1.Sum([]byte(ctx + "\x04" + text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Stale reports whether the translation of the message k was made from other text than src's, the primary
+// language's catalog, has now: that is, whether its metadata records a Source other than src's Fingerprint.
+func (c *Catalog) Stale(src *Catalog, k string) bool {
+	meta := c.Meta[k]
+	return meta != nil && meta.Source != "" && meta.Source != src.Fingerprint(k)
+}
+
+// Encode writes v as json as the catalog is written: without HTML escaping or a trailing newline, indenting nested
+// lines by prefix. Formats which share the catalog's layout, such as ARB, use it too.
+func Encode(buf *bytes.Buffer, v interface{}, prefix string) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+	return nil
+}
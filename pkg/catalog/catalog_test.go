@@ -0,0 +1,177 @@
+package catalog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mpictor/go-xtract/pkg/plural"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const withMeta = `{
+  "AA_NativeLangName": "English",
+  "HelloWorld": "Hello, <World>!",
+  "@HelloWorld": {
+    "references": [
+      "pkg/translatable.go:12",
+      "main.go:3"
+    ]
+  }
+}
+`
+
+func TestReadWrite(t *testing.T) {
+	c, err := Read([]byte(withMeta))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"AA_NativeLangName": "English",
+		"HelloWorld":        "Hello, <World>!",
+	}, c.Messages)
+	require.Contains(t, c.Meta, "HelloWorld")
+	assert.Equal(t, []string{"pkg/translatable.go:12", "main.go:3"}, c.Meta["HelloWorld"].References)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+	assert.Equal(t, withMeta, buf.String(), "must round-trip")
+}
+
+const withPlural = `{
+  "AA_NativeLangName": "polski",
+  "FilesDeleted": {
+    "one": "Usunięto %d plik",
+    "few": "Usunięto %d pliki",
+    "many": "Usunięto %d plików",
+    "other": "Usunięto %d pliku"
+  },
+  "@FilesDeleted": {
+    "context": "<trash>"
+  }
+}
+`
+
+func TestPlural(t *testing.T) {
+	c, err := Read([]byte(withPlural))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AA_NativeLangName", "FilesDeleted"}, c.Keys())
+	require.Contains(t, c.Plurals, "FilesDeleted")
+	assert.Equal(t, "Usunięto %d pliki", c.Plurals["FilesDeleted"][plural.Few])
+	assert.NotContains(t, c.Messages, "FilesDeleted")
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+	assert.Equal(t, withPlural, buf.String(), "must round-trip, with forms in CLDR order")
+
+	_, err = Read([]byte(`{"Key": {"several": "x"}}`))
+	assert.Error(t, err, "unknown category")
+}
+
+func TestWriteEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, New().Write(&buf))
+	assert.Equal(t, "{}\n", buf.String())
+}
+
+func TestReadInvalid(t *testing.T) {
+	_, err := Read([]byte(`{"Key": 1}`))
+	assert.Error(t, err, "message must be a string")
+
+	_, err = Read([]byte(`{"@Key": "string"}`))
+	assert.Error(t, err, "metadata must be an object")
+}
+
+const withObsolete = `{
+  "AA_NativeLangName": "Deutsch",
+  "Quit": "Beenden",
+  "@@obsolete": {
+    "Exit": "Verlassen",
+    "Files": {
+      "one": "%d Datei",
+      "other": "%d Dateien"
+    }
+  }
+}
+`
+
+func TestObsolete(t *testing.T) {
+	c, err := Read([]byte(withObsolete))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AA_NativeLangName", "Quit"}, c.Keys())
+	require.NotNil(t, c.Obsolete)
+	assert.Equal(t, []string{"Exit", "Files"}, c.Obsolete.Keys())
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+	assert.Equal(t, withObsolete, buf.String(), "must round-trip")
+}
+
+func TestSync(t *testing.T) {
+	src, err := Read([]byte(`{
+  "AA_NativeLangName": "English",
+  "Files": {"one": "%d file", "other": "%d files"},
+  "Quit": "Quit",
+  "Open": "Open",
+  "@Open": {"context": "verb"},
+  "Save": {"one": "Save %d", "other": "Save %d"}
+}`))
+	require.NoError(t, err)
+	tr, err := Read([]byte(withObsolete))
+	require.NoError(t, err)
+	tr.Messages["Save"] = "Speichern"
+	tr.Messages["Old"] = "Alt"
+	tr.Obsolete.Plurals["Files"] = Plural{plural.One: "%d Datei"}
+
+	out, ch := Sync(src, tr, plural.Forms("de"), true)
+	assert.Equal(t, Changes{
+		Added:     []string{"Open", "Save"},
+		Restored:  []string{"Files"},
+		Obsoleted: []string{"Old", "Save"},
+	}, ch)
+	assert.Equal(t, map[string]string{"AA_NativeLangName": "Deutsch", "Open": "", "Quit": "Beenden"}, out.Messages)
+	assert.Equal(t, map[string]Plural{
+		"Files": {plural.One: "%d Datei", plural.Other: ""},
+		"Save":  {plural.One: "", plural.Other: ""},
+	}, out.Plurals)
+	assert.Equal(t, "verb", out.Meta["Open"].Context)
+	require.NotNil(t, out.Obsolete)
+	assert.Equal(t, map[string]string{"Exit": "Verlassen", "Old": "Alt", "Save": "Speichern"}, out.Obsolete.Messages)
+	assert.Equal(t, Plural{plural.One: "%d Datei"}, tr.Obsolete.Plurals["Files"], "tr must not be modified")
+
+	again, ch := Sync(src, out, plural.Forms("de"), true)
+	assert.True(t, ch.Empty())
+	assert.Equal(t, out, again)
+
+	delete(src.Messages, "AA_NativeLangName")
+	out, ch = Sync(src, tr, plural.Forms("de"), false)
+	assert.Equal(t, []string{"Exit", "Old", "Save"}, ch.Deleted)
+	assert.Nil(t, out.Obsolete)
+	assert.Equal(t, "Deutsch", out.Messages[NameKey], "the language name must be kept")
+}
+
+func TestStale(t *testing.T) {
+	src, err := Read([]byte(`{"Hello": "Hello!", "Open": "Open", "@Open": {"context": "verb"}, "New": "New"}`))
+	require.NoError(t, err)
+	assert.Regexp(t, "^[0-9a-f]{16}$", src.Fingerprint("Hello"))
+	assert.NotEqual(t, src.Fingerprint("Open"), (&Catalog{Messages: src.Messages}).Fingerprint("Open"), "context matters")
+	assert.Equal(t, "", src.Fingerprint("Nope"))
+
+	tr, err := Read([]byte(`{"Hello": "Hallo!", "Open": "Öffnen", "New": ""}`))
+	require.NoError(t, err)
+	out, ch := Sync(src, tr, nil, true)
+	assert.Empty(t, ch.Stale)
+	assert.Equal(t, src.Fingerprint("Hello"), out.Meta["Hello"].Source, "translation taken to be of the current text")
+	assert.Equal(t, "verb", out.Meta["Open"].Context)
+	assert.NotContains(t, out.Meta, "New", "no source for an empty translation")
+
+	src.Messages["Hello"] = "Hello, World!"
+	assert.True(t, out.Stale(src, "Hello"))
+	assert.False(t, out.Stale(src, "Open"))
+	out, ch = Sync(src, out, nil, true)
+	assert.Equal(t, []string{"Hello"}, ch.Stale)
+	assert.Equal(t, "Hallo!", out.Messages["Hello"], "stale translations are kept")
+
+	out.Meta["Hello"].Source = ""
+	out, ch = Sync(src, out, nil, true)
+	assert.Empty(t, ch.Stale, "removing the source marks the translation as updated")
+	assert.False(t, out.Stale(src, "Hello"))
+}
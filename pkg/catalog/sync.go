@@ -0,0 +1,161 @@
+package catalog
+
+import (
+	"sort"
+
+	"github.com/mpictor/go-xtract/pkg/plural"
+)
+
+// Changes lists the keys of the messages changed by Sync, each sorted
+type Changes struct {
+	// new to the translation, and added empty
+	Added []string
+	// moved back from the obsolete messages
+	Restored []string
+	// moved to the obsolete messages
+	Obsoleted []string
+	// removed from the translation
+	Deleted []string
+	// kept, but translated from text which has since changed; see Catalog.Stale
+	Stale []string
+}
+
+// Empty reports whether nothing was changed or found stale
+func (c Changes) Empty() bool {
+	return len(c.Added)+len(c.Restored)+len(c.Obsoleted)+len(c.Deleted)+len(c.Stale) == 0
+}
+
+// Sync returns tr, a translation, updated to the messages of src, the primary language's catalog. Translations of
+// the messages in src are kept, and messages new to tr are added empty. Plural messages are given an empty form for
+// each of forms, the categories tr's language uses, which they lack. Messages of tr no longer in src are moved to
+// its obsolete messages if keepObsolete, and deleted otherwise, along with any already obsolete; obsolete messages
+// which are back in src are restored. A message which was plural and is no longer, or the reverse, is replaced as
+// if new.
+//
+// tr's NameKey is always kept. The metadata of the messages is src's, so translators see their context,
+// description and references, except for the Source of each translation. A translation without a Source is taken
+// to be of the text src has, and given its Fingerprint; one whose Source differs is reported as stale, and keeps
+// its Source until it is removed, to mark the translation as updated.
+func Sync(src, tr *Catalog, forms []plural.Category, keepObsolete bool) (*Catalog, Changes) {
+	var ch Changes
+	out := New()
+	obsolete := New()
+	if tr.Obsolete != nil {
+		obsolete.copy(tr.Obsolete, tr.Obsolete.Keys()...)
+	}
+	inSrc := make(map[string]bool)
+	for _, k := range src.Keys() {
+		inSrc[k] = true
+		_, srcPlural := src.Plurals[k]
+		switch {
+		case tr.has(k, srcPlural):
+			out.copy(tr, k)
+		case obsolete.has(k, srcPlural):
+			out.copy(obsolete, k)
+			obsolete.remove(k)
+			ch.Restored = append(ch.Restored, k)
+		case srcPlural:
+			out.Plurals[k] = make(Plural)
+			ch.Added = append(ch.Added, k)
+		default:
+			out.Messages[k] = ""
+			ch.Added = append(ch.Added, k)
+		}
+		if srcPlural {
+			out.addForms(k, forms)
+		}
+		meta := new(Meta)
+		if m := src.Meta[k]; m != nil {
+			*meta = *m
+		}
+		if m := out.Meta[k]; m != nil {
+			meta.Source = m.Source
+		}
+		switch {
+		case out.untranslated(k):
+			meta.Source = ""
+		case meta.Source == "":
+			meta.Source = src.Fingerprint(k)
+		case meta.Source != src.Fingerprint(k):
+			ch.Stale = append(ch.Stale, k)
+		}
+		delete(out.Meta, k)
+		if !meta.empty() {
+			out.Meta[k] = meta
+		}
+	}
+	for _, k := range tr.Keys() {
+		switch {
+		case k == NameKey && !inSrc[k]:
+			out.copy(tr, k)
+		case inSrc[k] && out.has(k, tr.Plurals[k] != nil):
+		case keepObsolete:
+			obsolete.copy(tr, k)
+			ch.Obsoleted = append(ch.Obsoleted, k)
+		default:
+			ch.Deleted = append(ch.Deleted, k)
+		}
+	}
+	if keepObsolete {
+		if len(obsolete.Keys()) > 0 {
+			out.Obsolete = obsolete
+		}
+	} else {
+		ch.Deleted = append(ch.Deleted, obsolete.Keys()...)
+		sort.Strings(ch.Deleted)
+	}
+	return out, ch
+}
+
+// has reports whether c has the message k, and whether it is plural is isPlural
+func (c *Catalog) has(k string, isPlural bool) bool {
+	if isPlural {
+		_, ok := c.Plurals[k]
+		return ok
+	}
+	_, ok := c.Messages[k]
+	return ok
+}
+
+// copy copies the messages with keys from other, with the Source of their metadata
+func (c *Catalog) copy(other *Catalog, keys ...string) {
+	for _, k := range keys {
+		if forms, ok := other.Plurals[k]; ok {
+			c.Plurals[k] = forms
+		} else {
+			c.Messages[k] = other.Messages[k]
+		}
+		if meta := other.Meta[k]; meta != nil && meta.Source != "" {
+			c.Meta[k] = &Meta{Source: meta.Source}
+		}
+	}
+}
+
+// untranslated reports whether the message k is empty, or has only empty forms
+func (c *Catalog) untranslated(k string) bool {
+	for _, form := range c.Plurals[k] {
+		if form != "" {
+			return false
+		}
+	}
+	return c.Messages[k] == ""
+}
+
+// addForms adds an empty form for each of forms which the plural message k lacks
+func (c *Catalog) addForms(k string, forms []plural.Category) {
+	p := make(Plural, len(forms))
+	for _, cat := range forms {
+		p[cat] = ""
+	}
+	for cat, form := range c.Plurals[k] {
+		p[cat] = form
+	}
+	c.Plurals[k] = p
+}
+
+// remove removes the message k, with its metadata
+func (c *Catalog) remove(k string) {
+	delete(c.Messages, k)
+	delete(c.Plurals, k)
+	delete(c.Meta, k)
+}
@@ -0,0 +1,331 @@
+// Package check compares the json language assets of a program with the asset of its primary language, reporting
+// every problem found in each rather than stopping at the first, along with how much of each is translated.
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/plural"
+)
+
+// Kind is a kind of problem
+type Kind string
+
+// The kinds of problem reported
+const (
+	// a message of the primary language is absent
+	Missing Kind = "missing"
+	// a message is absent from the primary language, so is no longer used
+	Obsolete Kind = "obsolete"
+	// a message, or a form of a plural message, is empty
+	Empty Kind = "empty"
+	// a message is the same as in the primary language
+	Untranslated Kind = "untranslated"
+	// a message was translated from text which has since changed; see catalog.Catalog.Stale
+	Stale Kind = "stale"
+	// a message's fmt verbs, such as %d or %[2]s, do not format the arguments of the primary language's
+	Verbs Kind = "verbs"
+	// a message's template placeholders, such as {{.Name}}, are not those of the primary language's
+	Placeholders Kind = "placeholders"
+	// catalog.NameKey is absent or empty
+	Name Kind = "name"
+)
+
+// Kinds lists all kinds of problem
+var Kinds = []Kind{Missing, Obsolete, Empty, Untranslated, Stale, Verbs, Placeholders, Name}
+
+// ParseKinds parses a comma-separated list of kinds. "all" stands for Kinds, and "none" or "" for no kinds.
+func ParseKinds(s string) (map[Kind]bool, error) {
+	kinds := make(map[Kind]bool)
+	for _, k := range strings.Split(s, ",") {
+		switch k = strings.TrimSpace(k); k {
+		case "", "none":
+		case "all":
+			for _, kind := range Kinds {
+				kinds[kind] = true
+			}
+		default:
+			if !Kind(k).valid() {
+				return nil, fmt.Errorf("unknown problem kind %s", k)
+			}
+			kinds[Kind(k)] = true
+		}
+	}
+	return kinds, nil
+}
+
+func (k Kind) valid() bool {
+	for _, kind := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Problem is a problem with a message, or with an asset as a whole if Key is empty
+type Problem struct {
+	Kind   Kind   `json:"kind"`
+	Key    string `json:"key,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (p Problem) String() string {
+	s := string(p.Kind)
+	if p.Key != "" {
+		s += " " + p.Key
+	}
+	if p.Detail != "" {
+		s += ": " + p.Detail
+	}
+	return s
+}
+
+// Asset is the result of checking one language asset
+type Asset struct {
+	File string `json:"file"`
+	// the value of catalog.NameKey
+	Name string `json:"name,omitempty"`
+	// messages of the primary language which are present, not empty, not stale and not the same as the source
+	Translated int `json:"translated"`
+	// messages of the primary language, excepting catalog.NameKey
+	Total int `json:"total"`
+	// Translated as a percentage of Total
+	Complete float64   `json:"complete"`
+	Problems []Problem `json:"problems"`
+}
+
+// Report is the result of checking the assets of all languages
+type Report struct {
+	// the primary language's asset, and any problems with it
+	Source   string    `json:"source"`
+	Problems []Problem `json:"problems"`
+	// the other assets, in the order checked
+	Assets []*Asset `json:"assets"`
+}
+
+// Source checks the asset of the primary language, which only has problems if it lacks the language name or has
+// empty messages
+func Source(file string, src *catalog.Catalog) *Report {
+	r := &Report{Source: file, Problems: []Problem{}, Assets: []*Asset{}}
+	if src.Messages[catalog.NameKey] == "" {
+		r.Problems = append(r.Problems, Problem{Kind: Name, Detail: "no " + catalog.NameKey})
+	}
+	for _, k := range src.Keys() {
+		if k != catalog.NameKey && isEmpty(src, k, nil) {
+			r.Problems = append(r.Problems, Problem{Kind: Empty, Key: k})
+		}
+	}
+	return r
+}
+
+// Compare checks the asset of a language against src, the primary language's, and adds the result to the report.
+// locale, such as "pt-BR", selects the plural forms required.
+func (r *Report) Compare(file, locale string, src, tr *catalog.Catalog) *Asset {
+	a := &Asset{File: file, Name: tr.Messages[catalog.NameKey], Problems: []Problem{}}
+	if a.Name == "" {
+		a.Problems = append(a.Problems, Problem{Kind: Name, Detail: "no " + catalog.NameKey})
+	}
+	forms := plural.Forms(locale)
+	for _, k := range src.Keys() {
+		if k == catalog.NameKey {
+			continue
+		}
+		a.Total++
+		if !has(tr, k) {
+			a.Problems = append(a.Problems, Problem{Kind: Missing, Key: k})
+			continue
+		}
+		if _, ok := tr.Plurals[k]; ok != (src.Plurals[k] != nil) {
+			detail := "not a plural message"
+			if ok {
+				detail = "a plural message"
+			}
+			a.Problems = append(a.Problems, Problem{Kind: Missing, Key: k, Detail: "translation is " + detail})
+			continue
+		}
+		if isEmpty(tr, k, forms) {
+			a.Problems = append(a.Problems, Problem{Kind: Empty, Key: k, Detail: emptyDetail(tr, k, forms)})
+			continue
+		}
+		stale := tr.Stale(src, k)
+		if stale {
+			a.Problems = append(a.Problems, Problem{Kind: Stale, Key: k, Detail: "source text changed since translated"})
+		}
+		var problems []Problem
+		if p, ok := src.Plurals[k]; ok {
+			problems = comparePlural(k, p, tr.Plurals[k])
+		} else {
+			problems = compareText(k, src.Messages[k], tr.Messages[k])
+		}
+		if !stale && !hasKind(problems, Untranslated) {
+			a.Translated++
+		}
+		a.Problems = append(a.Problems, problems...)
+	}
+	for _, k := range tr.Keys() {
+		if k != catalog.NameKey && !has(src, k) {
+			a.Problems = append(a.Problems, Problem{Kind: Obsolete, Key: k})
+		}
+	}
+	if a.Total > 0 {
+		a.Complete = 100 * float64(a.Translated) / float64(a.Total)
+	} else {
+		a.Complete = 100
+	}
+	r.Assets = append(r.Assets, a)
+	return a
+}
+
+// hasKind reports whether any of problems is of kind k
+func hasKind(problems []Problem, k Kind) bool {
+	for _, p := range problems {
+		if p.Kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether c has the message k, of either kind
+func has(c *catalog.Catalog, k string) bool {
+	if _, ok := c.Messages[k]; ok {
+		return true
+	}
+	_, ok := c.Plurals[k]
+	return ok
+}
+
+// isEmpty reports whether the message k is empty, or for a plural message, whether any of forms is; if forms is
+// nil, whether any form present is
+func isEmpty(c *catalog.Catalog, k string, forms []plural.Category) bool {
+	return emptyDetail(c, k, forms) != "" || c.Messages[k] == "" && c.Plurals[k] == nil
+}
+
+// emptyDetail lists the forms of the plural message k which are missing or empty
+func emptyDetail(c *catalog.Catalog, k string, forms []plural.Category) string {
+	p, ok := c.Plurals[k]
+	if !ok {
+		return ""
+	}
+	if forms == nil {
+		for _, cat := range plural.Categories {
+			if _, ok := p[cat]; ok {
+				forms = append(forms, cat)
+			}
+		}
+	}
+	var empty []string
+	for _, cat := range forms {
+		if p[cat] == "" {
+			empty = append(empty, string(cat))
+		}
+	}
+	if len(empty) == 0 {
+		return ""
+	}
+	return "no plural form " + strings.Join(empty, ", ")
+}
+
+// compareText checks the translation tr of the message k, whose text in the primary language is src. Verbs are
+// only compared if src is a format string.
+func compareText(k, src, tr string) []Problem {
+	var problems []Problem
+	if tr == src {
+		problems = append(problems, Problem{Kind: Untranslated, Key: k})
+	}
+	if isFormat(src) {
+		if d := compareVerbs(src, tr); d != "" {
+			problems = append(problems, Problem{Kind: Verbs, Key: k, Detail: d})
+		}
+	}
+	if d := comparePlaceholders(src, tr); d != "" {
+		problems = append(problems, Problem{Kind: Placeholders, Key: k, Detail: d})
+	}
+	return problems
+}
+
+// comparePlural checks the forms of the translation tr of the plural message k. A form may have the verbs and
+// placeholders of either form of the primary language, as languages differ in which counts they use each form for.
+// Verbs are compared if either form of the primary language is a format string.
+func comparePlural(k string, src, tr catalog.Plural) []Problem {
+	var problems []Problem
+	same := true
+	format := isFormat(src[plural.One]) || isFormat(src[plural.Other])
+	for _, cat := range plural.Categories {
+		form, ok := tr[cat]
+		if !ok {
+			continue
+		}
+		if form != src[plural.One] && form != src[plural.Other] {
+			same = false
+		}
+		if format && compareVerbs(src[plural.One], form) != "" {
+			if d := compareVerbs(src[plural.Other], form); d != "" {
+				problems = append(problems, Problem{Kind: Verbs, Key: k, Detail: string(cat) + ": " + d})
+			}
+		}
+		if comparePlaceholders(src[plural.One], form) != "" {
+			if d := comparePlaceholders(src[plural.Other], form); d != "" {
+				problems = append(problems, Problem{Kind: Placeholders, Key: k, Detail: string(cat) + ": " + d})
+			}
+		}
+	}
+	if same {
+		problems = append([]Problem{{Kind: Untranslated, Key: k}}, problems...)
+	}
+	return problems
+}
+
+// Failures returns the problems of the kinds given, and describes each asset less complete than minComplete percent
+func (r *Report) Failures(kinds map[Kind]bool, minComplete float64) []string {
+	var fails []string
+	for _, p := range r.Problems {
+		if kinds[p.Kind] {
+			fails = append(fails, r.Source+": "+p.String())
+		}
+	}
+	for _, a := range r.Assets {
+		if a.Complete < minComplete {
+			fails = append(fails, fmt.Sprintf("%s: %.1f%% complete, below %g%%", a.File, a.Complete, minComplete))
+		}
+		for _, p := range a.Problems {
+			if kinds[p.Kind] {
+				fails = append(fails, a.File+": "+p.String())
+			}
+		}
+	}
+	return fails
+}
+
+// WriteText writes the report for people to read: each asset with its completion, followed by its problems
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: source\n", r.Source)
+	for _, p := range r.Problems {
+		fmt.Fprintf(&b, "\t%s\n", p)
+	}
+	for _, a := range r.Assets {
+		name := ""
+		if a.Name != "" {
+			name = " (" + a.Name + ")"
+		}
+		fmt.Fprintf(&b, "%s%s: %d of %d translated, %.1f%%\n", a.File, name, a.Translated, a.Total, a.Complete)
+		for _, p := range a.Problems {
+			fmt.Fprintf(&b, "\t%s\n", p)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the report as indented json
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,178 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+)
+
+const source = `{
+  "AA_NativeLangName": "English",
+  "Deleted": {"one": "%d file deleted", "other": "%d files deleted"},
+  "Greeting": "Hello, %s!",
+  "OK": "OK",
+  "Quit": "Quit"
+}`
+
+const polish = `{
+  "AA_NativeLangName": "polski",
+  "Deleted": {"one": "Usunięto plik", "few": "Usunięto %d pliki", "many": "Usunięto %s plików"},
+  "Greeting": "Cześć, %d!",
+  "OK": "OK",
+  "Old": "Stary"
+}`
+
+func read(t *testing.T, s string) *catalog.Catalog {
+	c, err := catalog.Read([]byte(s))
+	require.NoError(t, err)
+	return c
+}
+
+func TestCompare(t *testing.T) {
+	r := Source("en.json", read(t, source))
+	assert.Empty(t, r.Problems)
+
+	a := r.Compare("pl.json", "pl", read(t, source), read(t, polish))
+	assert.Equal(t, "polski", a.Name)
+	assert.Equal(t, 4, a.Total)
+	assert.Equal(t, 1, a.Translated, "OK is the same as the source, so untranslated")
+	assert.Equal(t, 25.0, a.Complete)
+	assert.Equal(t, []Problem{
+		{Kind: Empty, Key: "Deleted", Detail: "no plural form other"},
+		{Kind: Verbs, Key: "Greeting", Detail: "argument 1 is %d, want %s"},
+		{Kind: Untranslated, Key: "OK"},
+		{Kind: Missing, Key: "Quit"},
+		{Kind: Obsolete, Key: "Old"},
+	}, a.Problems)
+
+	kinds, err := ParseKinds("missing,verbs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"pl.json: verbs Greeting: argument 1 is %d, want %s",
+		"pl.json: missing Quit",
+	}, r.Failures(kinds, 0))
+	assert.Equal(t, []string{"pl.json: 25.0% complete, below 75%"}, r.Failures(nil, 75))
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJSON(&buf))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, r, &decoded)
+}
+
+func TestStale(t *testing.T) {
+	src := read(t, `{"AA_NativeLangName": "English", "Hello": "Hello, World!", "Quit": "Quit"}`)
+	tr := read(t, `{"AA_NativeLangName": "Deutsch", "Hello": "Hallo!", "@Hello": {"source": "0123456789abcdef"},
+		"Quit": "Beenden", "@Quit": {"source": "`+src.Fingerprint("Quit")+`"}}`)
+	a := Source("en.json", src).Compare("de.json", "de", src, tr)
+	assert.Equal(t, []Problem{{Kind: Stale, Key: "Hello", Detail: "source text changed since translated"}}, a.Problems)
+	assert.Equal(t, 1, a.Translated)
+}
+
+func TestCopy(t *testing.T) {
+	src := read(t, source)
+	tr := read(t, strings.Replace(source, "English", "English (copy)", 1))
+	a := Source("en.json", src).Compare("en-gb.json", "en-GB", src, tr)
+	assert.Equal(t, 0, a.Translated)
+	assert.Equal(t, 0.0, a.Complete, "a copy of the source is not translated")
+	assert.Contains(t, a.Problems, Problem{Kind: Untranslated, Key: "Deleted"})
+	assert.Contains(t, a.Problems, Problem{Kind: Untranslated, Key: "Quit"})
+
+	tr.Messages["Quit"] = "Exit"
+	a = Source("en.json", src).Compare("en-gb.json", "en-GB", src, tr)
+	assert.Equal(t, 1, a.Translated)
+	assert.Equal(t, 25.0, a.Complete)
+}
+
+func TestComparePlural(t *testing.T) {
+	src := read(t, source)
+	tr := read(t, `{"Deleted": {"one": "Usunięto plik", "few": "Usunięto %d pliki", "many": "Usunięto %s plików", "other": "%d"},
+		"Greeting": {"other": "Cześć"}}`)
+	a := Source("en.json", src).Compare("pl.json", "pl", src, tr)
+	assert.Contains(t, a.Problems, Problem{Kind: Verbs, Key: "Deleted", Detail: "many: argument 1 is %s, want %d"})
+	assert.Contains(t, a.Problems, Problem{Kind: Missing, Key: "Greeting", Detail: "translation is a plural message"})
+	assert.Contains(t, a.Problems, Problem{Kind: Name, Detail: "no AA_NativeLangName"})
+	assert.Contains(t, a.Problems, Problem{Kind: Verbs, Key: "Deleted", Detail: "one: argument 1 (%d) is not used"},
+		"Sprintf would append the unused count")
+
+	r := Source("en.json", read(t, `{"Greeting": "", "Deleted": {"one": "a", "other": ""}}`))
+	assert.Equal(t, []Problem{
+		{Kind: Name, Detail: "no AA_NativeLangName"},
+		{Kind: Empty, Key: "Deleted"},
+		{Kind: Empty, Key: "Greeting"},
+	}, r.Problems)
+}
+
+func TestVerbs(t *testing.T) {
+	assert.Equal(t, []directive{{1, 'd'}, {2, 's'}, {3, '*'}, {4, 'v'}, {2, 'q'}, {3, 'f'}},
+		directives("%-5d%% of %s at %.*v, %[2]q %f%"))
+	assert.Equal(t, []directive{{1, 'é'}}, directives("%é"))
+
+	for tr, want := range map[string]string{
+		"%d — %s":         "argument 1 is %d, want %s; argument 2 is %s, want %d",
+		"%[2]d — %[1]s":   "",
+		"%[2]x %[1]v":     "",
+		"%[2]d files":     "argument 1 (%s) is not used",
+		"%s: %d, %d":      "argument 3 (%d) is not given",
+		"%s: %t":          "argument 2 is %t, want %d",
+		"%[1]s: %[2]T":    "",
+		"%s: %[1]s %[2]d": "",
+	} {
+		assert.Equal(t, want, compareVerbs("%s: %d", tr), tr)
+	}
+	assert.Empty(t, directives("100% sure, 100 %"))
+	assert.Equal(t, []directive{{1, 'd'}}, directives("%d% done"))
+	assert.Equal(t, "", compareVerbs("%*.*f", "%[1]*.[2]*[3]f"))
+	assert.Equal(t, "argument 1 is %f, want %*; argument 3 is %*, want %f", compareVerbs("%*.*f", "%[3]*.[2]*[1]f"))
+}
+
+func TestPlaceholders(t *testing.T) {
+	assert.Equal(t, []string{"$u.Name", ".Count", ".User.Name"},
+		placeholders("{{.User.Name}} has {{- .Count}} {{ range $u := .Count }}{{$u.Name}}{{.Count}}{{end}}"))
+
+	src := "Hello, {{.Name}}! You have {{.Count}} messages"
+	for tr, want := range map[string]string{
+		"{{.Count}} Nachrichten für {{.Name}}": "",
+		"Hallo, {{.Name}}!":                    "has {{.Name}}, want {{.Count}} {{.Name}}",
+		"Hallo, {{.Nom}}! {{.Count}}":          "has {{.Count}} {{.Nom}}, want {{.Count}} {{.Name}}",
+		"Hallo, {{.Name}! {{.Count}}":          `1: bad character U+007D '}'`,
+		"Hallo":                                "has no placeholders, want {{.Count}} {{.Name}}",
+	} {
+		assert.Equal(t, want, comparePlaceholders(src, tr), tr)
+	}
+	assert.Equal(t, "", comparePlaceholders("100%", "100 %"), "not a template")
+
+	src2 := read(t, `{"Greeting": "Hello, {{.Name}}!", "Deleted": {"one": "a file", "other": "{{.N}} files"}}`)
+	tr := read(t, `{"Greeting": "Hallo, {{.Nom}}!", "Deleted": {"one": "eine Datei", "other": "{{.X}} Dateien"}}`)
+	a := Source("en.json", src2).Compare("de.json", "de", src2, tr)
+	assert.Contains(t, a.Problems, Problem{Kind: Placeholders, Key: "Greeting", Detail: "has {{.Nom}}, want {{.Name}}"})
+	assert.Contains(t, a.Problems, Problem{Kind: Placeholders, Key: "Deleted", Detail: "other: has {{.X}}, want {{.N}}"})
+	assert.Len(t, a.Problems, 3)
+}
+
+func TestNotFormat(t *testing.T) {
+	src := read(t, `{"AA_NativeLangName": "English", "Sure": "100% sure", "Done": "Done",
+		"Percent": {"one": "1%", "other": "%d%%"}}`)
+	tr := read(t, `{"AA_NativeLangName": "Deutsch", "Sure": "sicher zu 100 %", "Done": "Fertig zu 100%s",
+		"Percent": {"one": "1 %", "other": "%s %%"}}`)
+	a := Source("en.json", src).Compare("de.json", "de", src, tr)
+	assert.Equal(t, []Problem{{Kind: Verbs, Key: "Percent", Detail: "other: argument 1 is %s, want %d"}}, a.Problems,
+		"only format strings are compared")
+}
+
+func TestParseKinds(t *testing.T) {
+	kinds, err := ParseKinds("all")
+	require.NoError(t, err)
+	assert.Len(t, kinds, len(Kinds))
+	kinds, err = ParseKinds("none")
+	require.NoError(t, err)
+	assert.Empty(t, kinds)
+	_, err = ParseKinds("missing,typo")
+	assert.Error(t, err)
+}
@@ -0,0 +1,213 @@
+package check
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// directive is a fmt verb and the argument it formats, numbered from 1
+type directive struct {
+	arg  int
+	verb rune
+}
+
+// directives parses the fmt verbs in s. Arguments are numbered as fmt does: each verb, and each '*' width or
+// precision, takes the next argument, and an index such as %[2]d sets the argument taken. A '*' takes an int,
+// and is given as verb '*'. %% takes no argument. Unlike fmt, a '%' followed by a space or at the end of s is
+// taken to be literal, as in "100% sure" or "100 %", not a verb with the space flag.
+func directives(s string) []directive {
+	var ds []directive
+	arg := 1
+	// index parses an argument index at s[i:], such as [2], returning the index of the byte after it
+	index := func(i int) int {
+		if i < len(s) && s[i] == '[' {
+			if end := strings.IndexByte(s[i:], ']'); end > 0 {
+				var n int
+				if _, err := fmt.Sscanf(s[i+1:i+end], "%d", &n); err == nil && n > 0 {
+					arg = n
+				}
+				return i + end + 1
+			}
+		}
+		return i
+	}
+	// width parses a width or precision at s[i:], returning the index of the byte after it
+	width := func(i int) int {
+		i = index(i)
+		if i < len(s) && s[i] == '*' {
+			ds = append(ds, directive{arg, '*'})
+			arg++
+			return i + 1
+		}
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		i++
+		if i == len(s) || s[i] == ' ' {
+			continue
+		}
+		for i < len(s) && strings.IndexByte("+-# 0", s[i]) >= 0 {
+			i++
+		}
+		i = width(i)
+		if i < len(s) && s[i] == '.' {
+			i = width(i + 1)
+		}
+		i = index(i)
+		if i >= len(s) {
+			break
+		}
+		verb, size := utf8.DecodeRuneInString(s[i:])
+		if verb != '%' {
+			ds = append(ds, directive{arg, verb})
+			arg++
+		}
+		i += size - 1
+	}
+	return ds
+}
+
+// isFormat reports whether s is a format string, with at least one verb. Other messages may have a '%' which
+// is not one, such as "100%", and are not formatted, so their verbs are not compared.
+func isFormat(s string) bool { return len(directives(s)) > 0 }
+
+// verbClasses are sets of verbs which format operands of the same types: strings and []byte, integers, floats,
+// bools and pointers. Verbs in a class together accept the same arguments. v and T accept any.
+var verbClasses = []string{"sqxXv", "dboOcUqxXv*", "eEfFgGxXbv", "tv", "pv"}
+
+// compatible reports whether there is an argument which both verbs format
+func compatible(a, b rune) bool {
+	if a == b || a == 'v' || b == 'v' || a == 'T' || b == 'T' {
+		return true
+	}
+	for _, class := range verbClasses {
+		if strings.ContainsRune(class, a) && strings.ContainsRune(class, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareVerbs describes how the verbs of tr are incompatible with those of src, or returns "" if they are not.
+// tr may use the arguments in another order, given by indexes such as %[2]s, but must use each argument src uses,
+// and no others, with verbs compatible with src's.
+func compareVerbs(src, tr string) string {
+	sv, tv := verbsByArg(src), verbsByArg(tr)
+	var diffs []string
+	for _, arg := range args(sv, tv) {
+		switch {
+		case tv[arg] == nil:
+			diffs = append(diffs, fmt.Sprintf("argument %d (%s) is not used", arg, verbList(sv[arg])))
+		case sv[arg] == nil:
+			diffs = append(diffs, fmt.Sprintf("argument %d (%s) is not given", arg, verbList(tv[arg])))
+		default:
+			for _, t := range tv[arg] {
+				for _, s := range sv[arg] {
+					if !compatible(s, t) {
+						diffs = append(diffs, fmt.Sprintf("argument %d is %s, want %s", arg, verbList(tv[arg]), verbList(sv[arg])))
+						break
+					}
+				}
+			}
+		}
+	}
+	return strings.Join(unique(diffs), "; ")
+}
+
+// verbsByArg maps the arguments used by the fmt verbs in s to the verbs formatting them
+func verbsByArg(s string) map[int][]rune {
+	m := make(map[int][]rune)
+	for _, d := range directives(s) {
+		m[d.arg] = append(m[d.arg], d.verb)
+	}
+	return m
+}
+
+// args returns the arguments in either map, sorted
+func args(a, b map[int][]rune) []int {
+	var all []int
+	for arg := range a {
+		all = append(all, arg)
+	}
+	for arg := range b {
+		if a[arg] == nil {
+			all = append(all, arg)
+		}
+	}
+	sort.Ints(all)
+	return all
+}
+
+func verbList(verbs []rune) string {
+	strs := make([]string, len(verbs))
+	for i, v := range verbs {
+		strs[i] = "%" + string(v)
+	}
+	return strings.Join(unique(strs), " ")
+}
+
+// unique removes repeats from strs, keeping the first of each
+func unique(strs []string) []string {
+	var u []string
+	seen := make(map[string]bool, len(strs))
+	for _, s := range strs {
+		if !seen[s] {
+			seen[s] = true
+			u = append(u, s)
+		}
+	}
+	return u
+}
+
+var (
+	// an action of a text/template
+	action = regexp.MustCompile(`{{(.*?)}}`)
+	// a field or method of the data, or of a variable, in an action, such as .Name or $user.Name
+	field = regexp.MustCompile(`\$?[\pL\pN_]*(?:\.[\pL_][\pL\pN_]*)+`)
+)
+
+// placeholders returns the fields of the data used by the template actions in s, such as .Name in {{.Name}},
+// sorted and without repeats
+func placeholders(s string) []string {
+	var fields []string
+	for _, a := range action.FindAllStringSubmatch(s, -1) {
+		fields = append(fields, field.FindAllString(a[1], -1)...)
+	}
+	sort.Strings(fields)
+	return unique(fields)
+}
+
+// comparePlaceholders describes how the template placeholders of tr differ from those of src, or returns "" if
+// they do not. If src is a valid template, so must tr be. Strings without "{{" are not templates, and not compared.
+func comparePlaceholders(src, tr string) string {
+	if !strings.Contains(src, "{{") && !strings.Contains(tr, "{{") {
+		return ""
+	}
+	if _, err := template.New("").Parse(src); err == nil {
+		if _, err := template.New("").Parse(tr); err != nil {
+			return strings.TrimPrefix(err.Error(), "template: :")
+		}
+	}
+	sp, tp := placeholders(src), placeholders(tr)
+	if strings.Join(sp, " ") == strings.Join(tp, " ") {
+		return ""
+	}
+	return fmt.Sprintf("has %s, want %s", fieldList(tp), fieldList(sp))
+}
+
+func fieldList(fields []string) string {
+	if len(fields) == 0 {
+		return "no placeholders"
+	}
+	return "{{" + strings.Join(fields, "}} {{") + "}}"
+}
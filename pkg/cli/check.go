@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	fp "path/filepath"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/check"
+)
+
+// checkFiles checks all json files in the dir containing fname against it, writing the report in the given format
+// to -o. It exits with an error after writing the report if any problem is of the kinds in failOn, or if any
+// language is less than minComplete percent translated.
+func checkFiles(fname, reportFormat, failOn string, minComplete float64) {
+	if !strings.HasSuffix(fname, ".json") {
+		fatalf("-c: name must end with .json")
+	}
+	kinds, err := check.ParseKinds(failOn)
+	if err != nil {
+		fatalf("-fail-on: %s", err)
+	}
+	var write func(r *check.Report, w io.Writer) error
+	switch reportFormat {
+	case "text":
+		write = (*check.Report).WriteText
+	case "json":
+		write = (*check.Report).WriteJSON
+	default:
+		fatalf("-report: unknown format %s; must be text or json", reportFormat)
+	}
+
+	src, err := readCatalog(fname)
+	if err != nil {
+		fatalf("-c: %s", err)
+	}
+	if len(src.Keys()) == 0 {
+		fatalf("-c: no messages read from %s", fname)
+	}
+	r := check.Source(fname, src)
+	for _, f := range siblings(fname) {
+		tr, err := readCatalog(f)
+		if err != nil {
+			fatalf("-c: %s", err)
+		}
+		r.Compare(f, locale(f), src, tr)
+	}
+	writeOutput(*outputFile, func(w io.Writer) error { return write(r, w) })
+
+	fails := r.Failures(kinds, minComplete)
+	for _, f := range fails {
+		fmt.Fprintf(os.Stderr, "xtract: %s\n", f)
+	}
+	if len(fails) > 0 {
+		fatalf("-c: %d problem(s) fail the check", len(fails))
+	}
+}
+
+// readCatalog reads a json language asset
+func readCatalog(fname string) (*catalog.Catalog, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	c, err := catalog.Read(data)
+	if err != nil {
+		return nil, fmt.Errorf("json error in %s: %w", fname, err)
+	}
+	return c, nil
+}
+
+// siblings returns the other json files in the dir containing fname, sorted
+func siblings(fname string) []string {
+	var others []string
+	files, _ := fp.Glob(fp.Join(fp.Dir(fname), "*.json"))
+	for _, f := range files {
+		if f != fp.Clean(fname) {
+			others = append(others, f)
+		}
+	}
+	return others
+}
+
+// locale returns the locale of a json language asset, which is its name, such as "pt-BR" for pt-BR.json
+func locale(fname string) string {
+	return strings.TrimSuffix(fp.Base(fname), ".json")
+}
@@ -0,0 +1,292 @@
+// Package cli implements the xtract command. It is a package, rather than part of cmd/xtract, so that xtract can
+// be built with additional output formats registered; see package format.
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	fp "path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/extractor"
+	"github.com/mpictor/go-xtract/pkg/format"
+	"github.com/mpictor/go-xtract/pkg/util"
+)
+
+const (
+	stdoutSentinel = "<stdout>"
+	compareHelp    = "check all json files in the dir containing `file` against it, the primary language's,\n" +
+		"reporting messages missing, obsolete, empty, untranslated, stale or with mismatched\n" +
+		"format verbs or template placeholders, and assets without AA_NativeLangName. Only\n" +
+		"checks - run with -j first to create/update the primary language's file"
+)
+
+var (
+	targetFuncs    = extractor.NewTargetList(extractor.DefaultTargets...)
+	outputTemplate = flag.String("template", format.DefaultTemplate, "output template, for -format template")
+	outputJson     = flag.Bool("j", false, "output json; same as -format json")
+	outputFormat   = flag.String("format", "", "output `format`")
+	outputPOT      = flag.Bool("pot", false, "output a gettext .pot template, with references and translator comments; same as -format pot")
+	outputXLIFF    = flag.String("xliff", "", "output XLIFF `version` 1.2 or 2.0, for each of -lang - ignores template and -j")
+	sourceLang     = flag.String("srclang", "en", "source language of -xliff output, and -format output which records it")
+	targetLangs    = flag.String("lang", "", "comma-separated target languages of -xliff output. With more than one,\n-o must be a directory, and <lang>.xlf is written there for each")
+	importXLIFFs   = flag.String("import-xliff", "", "import translations from the XLIFF files matching `pattern` into the\njson assets in -assets, reporting segments missing or not final")
+	assetsDir      = flag.String("assets", ".", "directory of the json assets written by -import-xliff, as <lang>.json")
+	langName       = flag.String("lang-name", "", "AA_NativeLangName of the json asset -import-xliff creates, if the target language has\nnone yet; required then")
+	updatePO       = flag.String("update", "", "update the gettext .po files matching `pattern` with the extracted strings, like msgmerge:\nexisting translations are kept, and those of changed strings marked fuzzy")
+	keyStrategy    = flag.String("keys", "var", "`strategy` deriving the keys of json and other catalog output: var (the const/var name,\nelse text), pkgvar (var qualified by package name, else text), pathvar (var qualified by\nimport path, or its -pkg-alias, else text), text, hash, or id\n(given by an //xtract:id comment, else var). Keys shared by distinct messages are an error")
+	keyReport      = flag.String("key-report", "", "write the key of every message to `file`, as tab-separated key, context, text and vars")
+	sortOrder      = flag.String("sort", "", "`order` of output: key, position (of first use, by file name) or file (of first use,\nin the order files are given). Default position for pot, key otherwise")
+	outputRefs     = flag.Bool("refs", false, "include source references in json output, as '@key' metadata, and in -format output")
+	outputComments = flag.Bool("comments", false, "include translator comments in json output, as '@key' metadata, and in -format output")
+	commentPrefix  = flag.String("comment-prefix", extractor.DefaultCommentPrefix, "prefix marking comments near calls as meant for translators.\nEmpty for all comments. Comments on const/var declarations are always used")
+	outputFile     = flag.String("o", stdoutSentinel, "output file")
+	debug          = flag.Bool("v", false, "enable debug output")
+	strict         = flag.Bool("strict", false, "exit with an error if any call to the target func could not be extracted")
+	typed          = flag.Bool("typed", false, "use type information to find calls to the target func, including\ndot-imports, calls within its own package and calls through variables")
+	compare        = flag.String("c", "", compareHelp)
+	reportFormat   = flag.String("report", "text", "`format` of the -c report: text or json")
+	failOn         = flag.String("fail-on", "missing", "comma-separated `kinds` of problem which fail -c: missing, obsolete, empty,\nuntranslated, stale, verbs, placeholders, name, all or none")
+	minComplete    = flag.Float64("min-complete", 0, "fail -c if any language has less than `percent` of messages translated")
+	syncFile       = flag.String("sync", "", "update all json files in the dir containing `file` to its messages, the primary language's:\nnew messages are added empty, and translations and AA_NativeLangName are kept. Only updates -\nrun with -j first to create/update the primary language's file")
+	syncObsolete   = flag.String("obsolete", "keep", "`action` of -sync for messages no longer used: keep, moving them to \"@@obsolete\", or delete")
+	dryRun         = flag.Bool("dry-run", false, "print the changes -sync would make, without making them")
+)
+
+func init() {
+	flag.Var(targetFuncs, "func", "target func `path/to/pkg.Func[:ARGS]`; may be repeated. Methods are given as\n"+
+		"'(*path/to/pkg.Type).Method' and imply -typed. ARGS are 1-based argument\n"+
+		"positions as in xgettext's --keyword: the message (N), its plural form\n"+
+		"(second N) and its context (Nc), e.g. 'errs.New:2'")
+	flag.Var(pkgAliases, "pkg-alias", "`path=alias` shortening the import path qualifying -keys pathvar, for path and the\n"+
+		"packages below it; may be repeated. An empty alias leaves keys unqualified")
+}
+
+// aliasMap is a repeatable flag of path=alias pairs
+type aliasMap map[string]string
+
+var pkgAliases = make(aliasMap)
+
+func (a aliasMap) String() string {
+	pairs := make([]string, 0, len(a))
+	for path, alias := range a {
+		pairs = append(pairs, path+"="+alias)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (a aliasMap) Set(value string) error {
+	path, alias, ok := strings.Cut(value, "=")
+	if !ok || path == "" {
+		return fmt.Errorf("want path=alias, got %q", value)
+	}
+	a[path] = alias
+	return nil
+}
+
+// Main runs xtract with the command line arguments
+func Main() {
+	flag.Lookup("format").Usage = "output `format`: " + strings.Join(format.Names(), ", ") + ". Default template,\n" +
+		"or json with -j, or pot with -pot"
+	flag.Parse()
+
+	if *debug {
+		util.Log.SetOutput(os.Stdout)
+	}
+
+	if len(*compare) > 0 {
+		checkFiles(*compare, *reportFormat, *failOn, *minComplete)
+		return
+	}
+	if len(*syncFile) > 0 {
+		syncFiles(*syncFile, *syncObsolete, *dryRun)
+		return
+	}
+	if len(*importXLIFFs) > 0 {
+		importXLIFF(*importXLIFFs, *assetsDir, *langName)
+		return
+	}
+
+	name := *outputFormat
+	switch {
+	case name != "":
+	case *outputPOT:
+		name = "pot"
+	case *outputJson:
+		name = "json"
+	default:
+		name = "template"
+	}
+	writerFormat, ok := format.Lookup(name)
+	if !ok {
+		fatalf("-format: unknown format %s; must be one of %s", name, strings.Join(format.Names(), ", "))
+	}
+	keys, err := format.LookupKeyStrategy(*keyStrategy)
+	if err != nil {
+		fatalf("-keys: %s", err)
+	}
+	if len(pkgAliases) > 0 {
+		if *keyStrategy != "pathvar" {
+			fatalf("-pkg-alias requires -keys pathvar")
+		}
+		keys = format.PathVarKey(pkgAliases)
+	}
+	var order format.Order
+	if *sortOrder != "" {
+		var err error
+		if order, err = format.ParseOrder(*sortOrder); err != nil {
+			fatalf("-sort: %s", err)
+		}
+	}
+
+	targets, err := targetFuncs.Targets()
+	if err != nil {
+		fatalf("-func: %s", err)
+	}
+
+	if flag.NArg() == 0 {
+		fatalf("one or more file patterns must be provided")
+	}
+	globs := fixupGlobs()
+
+	files, err := util.FilesFromPatterns(globs...)
+	if err != nil {
+		fatalf("error resolving one more provide file pattern: %s", err.Error())
+	}
+	if len(files) == 0 {
+		fatalf("found 0 files in globs %v", globs)
+	}
+
+	for _, t := range targets {
+		if t.IsMethod() && !*typed {
+			// method calls can only be matched using type information
+			util.Log.Printf("method target %s: enabling -typed", t)
+			*typed = true
+		}
+	}
+	ext := extractor.NewTargets(targets...)
+	if *typed {
+		ext = extractor.NewTypedTargets(targets...)
+	}
+	ext.SetCommentPrefix(*commentPrefix)
+	if err := extractor.ProcessFiles(ext, files...); err != nil {
+		fatalf("%s", err)
+	}
+	diags := ext.Diagnostics()
+	for _, d := range diags {
+		fmt.Fprintf(os.Stderr, "xtract: warning: %s\n", d)
+	}
+	if *strict && len(diags) > 0 {
+		fatalf("%d call(s) to the target func could not be extracted", len(diags))
+	}
+
+	msgs := &format.Messages{
+		Vars:  relativeRefs(ext.Vars()),
+		Files: relativePaths(files),
+		Options: format.Options{
+			SourceLang: *sourceLang,
+			Template:   *outputTemplate,
+			Refs:       *outputRefs,
+			Comments:   *outputComments,
+			Order:      order,
+			Keys:       keys,
+		},
+	}
+
+	if *keyReport != "" {
+		writeOutput(*keyReport, func(w io.Writer) error { return format.WriteKeyReport(w, msgs) })
+	}
+
+	if *updatePO != "" {
+		updatePOFiles(msgs, *updatePO)
+		if *outputFormat == "" && !*outputJson && !*outputPOT && *outputFile == stdoutSentinel {
+			return
+		}
+	}
+
+	if *outputXLIFF != "" {
+		var langs []string
+		if *targetLangs != "" {
+			langs = strings.Split(*targetLangs, ",")
+		}
+		xliffOut(msgs, *outputXLIFF, langs)
+		return
+	}
+
+	writeOutput(*outputFile, func(w io.Writer) error { return writerFormat.Write(w, msgs) })
+}
+
+// writeOutput writes to the named file, or to stdout for stdoutSentinel, with write. Nothing is written if write
+// fails, leaving any existing file as it was.
+func writeOutput(fname string, write func(io.Writer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		fatalf("%s", err)
+	}
+	if fname == stdoutSentinel {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(fname, buf.Bytes(), 0666); err != nil {
+		fatalf("%s", err)
+	}
+}
+
+// relativeRefs makes the file names in references relative to the working dir, where possible
+func relativeRefs(vars extractor.VarList) extractor.VarList {
+	for _, v := range vars {
+		for i, r := range v.Refs {
+			v.Refs[i].File = relativePath(r.File)
+		}
+	}
+	return vars
+}
+
+// relativePaths makes file names relative to the working dir, where possible
+func relativePaths(files []string) []string {
+	rel := make([]string, len(files))
+	for i, f := range files {
+		rel[i] = relativePath(f)
+	}
+	return rel
+}
+
+func relativePath(fname string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fname
+	}
+	if rel, err := fp.Rel(wd, fname); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return fname
+}
+
+// fatalf prints to stderr regardless of -v, then exits
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "xtract: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+//get globs; if any are not absolute, fix.
+func fixupGlobs() []string {
+	globs := flag.Args()
+	sep := string(os.PathSeparator)
+	wd, err := os.Getwd()
+	if err != nil {
+		fatalf("getting working dir: %s", err)
+	}
+	wd += sep
+	for i := range globs {
+		if !strings.HasPrefix(globs[i], sep) {
+			util.Log.Printf("fix glob %s add %s", globs[i], wd)
+			globs[i] = wd + globs[i]
+		}
+	}
+	return globs
+}
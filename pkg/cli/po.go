@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	fp "path/filepath"
+
+	"github.com/mpictor/go-xtract/pkg/format"
+	"github.com/mpictor/go-xtract/pkg/po"
+)
+
+// updatePOFiles merges the extracted strings into each .po file matching pattern, keeping existing
+// translations and marking those of changed strings fuzzy. Each file is reported as updated or up to date, and
+// only written if changed.
+func updatePOFiles(msgs *format.Messages, pattern string) {
+	files, err := fp.Glob(pattern)
+	if err != nil {
+		fatalf("-update: %s", err)
+	}
+	if len(files) == 0 {
+		fatalf("-update: no files match %s", pattern)
+	}
+	pot := format.POTFile(msgs.Sorted(format.OrderPosition))
+	for _, fname := range files {
+		data, err := os.ReadFile(fname)
+		if err != nil {
+			fatalf("-update: %s", err)
+		}
+		existing, err := po.ReadBytes(data)
+		if err != nil {
+			fatalf("-update: %s: %s", fname, err)
+		}
+		var buf bytes.Buffer
+		if err := po.Merge(existing, pot).Write(&buf); err != nil {
+			fatalf("-update: %s: %s", fname, err)
+		}
+		if bytes.Equal(data, buf.Bytes()) {
+			fmt.Printf("%s: up to date\n", fname)
+			continue
+		}
+		if err := os.WriteFile(fname, buf.Bytes(), 0666); err != nil {
+			fatalf("-update: %s", err)
+		}
+		fmt.Printf("%s: updated\n", fname)
+	}
+}
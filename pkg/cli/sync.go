@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/plural"
+)
+
+// syncFiles updates all json files in the dir containing fname to the messages in it, keeping their translations.
+// Messages no longer used are kept as obsolete or deleted, as given by obsolete. The changes to each file are
+// printed; with dryRun, the files are not written.
+func syncFiles(fname, obsolete string, dryRun bool) {
+	if !strings.HasSuffix(fname, ".json") {
+		fatalf("-sync: name must end with .json")
+	}
+	if obsolete != "keep" && obsolete != "delete" {
+		fatalf("-obsolete: must be keep or delete, not %s", obsolete)
+	}
+	src, err := readCatalog(fname)
+	if err != nil {
+		fatalf("-sync: %s", err)
+	}
+	if len(src.Keys()) == 0 {
+		fatalf("-sync: no messages read from %s", fname)
+	}
+	for _, f := range siblings(fname) {
+		tr, err := readCatalog(f)
+		if err != nil {
+			fatalf("-sync: %s", err)
+		}
+		synced, ch := catalog.Sync(src, tr, plural.Forms(locale(f)), obsolete == "keep")
+		printChanges(f, ch)
+		if dryRun {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := synced.Write(&buf); err != nil {
+			fatalf("-sync: %s: %s", f, err)
+		}
+		if old, err := os.ReadFile(f); err == nil && bytes.Equal(old, buf.Bytes()) {
+			continue
+		}
+		if err := os.WriteFile(f, buf.Bytes(), 0666); err != nil {
+			fatalf("-sync: %s", err)
+		}
+	}
+}
+
+// printChanges prints the number of messages changed in each way, followed by their keys
+func printChanges(fname string, ch catalog.Changes) {
+	if ch.Empty() {
+		fmt.Printf("%s: up to date\n", fname)
+		return
+	}
+	changes := []struct {
+		name string
+		keys []string
+	}{
+		{"added", ch.Added},
+		{"restored", ch.Restored},
+		{"obsolete", ch.Obsoleted},
+		{"deleted", ch.Deleted},
+		{"stale", ch.Stale},
+	}
+	var counts []string
+	for _, c := range changes {
+		if len(c.keys) > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", len(c.keys), c.name))
+		}
+	}
+	fmt.Printf("%s: %s\n", fname, strings.Join(counts, ", "))
+	for _, c := range changes {
+		for _, k := range c.keys {
+			fmt.Printf("\t%s %s\n", c.name, k)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	fp "path/filepath"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/format"
+	"github.com/mpictor/go-xtract/pkg/plural"
+	"github.com/mpictor/go-xtract/pkg/xliff"
+)
+
+// xliffOut writes the extracted strings as an XLIFF document for each target language. With more than one, the
+// output file is a directory holding <lang>.xlf for each.
+func xliffOut(msgs *format.Messages, version string, langs []string) {
+	if version != xliff.Version12 && version != xliff.Version20 {
+		fatalf("-xliff: unsupported version %s; must be %s or %s", version, xliff.Version12, xliff.Version20)
+	}
+	// references and comments are always included, as notes
+	opts := msgs.Options
+	opts.Refs, opts.Comments = true, true
+	c, err := format.BuildCatalog(msgs.Vars, opts)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	keys := msgs.Keys(format.OrderKey)
+	if len(langs) <= 1 {
+		lang := ""
+		if len(langs) == 1 {
+			lang = langs[0]
+		}
+		writeOutput(*outputFile, func(w io.Writer) error { return xliffDoc(c, keys, version, lang).Write(w) })
+		return
+	}
+	if *outputFile == stdoutSentinel {
+		fatalf("-xliff: -o must name a directory when there is more than one -lang")
+	}
+	if err := os.MkdirAll(*outputFile, 0755); err != nil {
+		fatalf("-xliff: %s", err)
+	}
+	for _, lang := range langs {
+		doc := xliffDoc(c, keys, version, lang)
+		writeOutput(fp.Join(*outputFile, lang+".xlf"), doc.Write)
+	}
+}
+
+// xliffDoc converts the messages of a catalog with the given keys to XLIFF, in that order. Each form of a plural
+// message which lang uses is a separate unit, with the id 'key[category]'.
+func xliffDoc(c *catalog.Catalog, keys []string, version, lang string) *xliff.Document {
+	doc := &xliff.Document{
+		Version:    version,
+		SourceLang: *sourceLang,
+		TargetLang: lang,
+		Original:   "messages",
+	}
+	for _, k := range keys {
+		u := xliff.Unit{ID: k, State: xliff.StateInitial}
+		if meta := c.Meta[k]; meta != nil {
+			u.Context = meta.Context
+			u.Locations = meta.References
+			if meta.Description != "" {
+				u.Notes = []string{meta.Description}
+			}
+		}
+		src, isPlural := c.Plurals[k]
+		if !isPlural {
+			u.Source = c.Messages[k]
+			doc.Units = append(doc.Units, u)
+			continue
+		}
+		forms := []plural.Category{plural.One, plural.Other}
+		if lang != "" {
+			forms = plural.Forms(lang)
+		}
+		for _, cat := range forms {
+			pu := u
+			pu.ID = pluralID(k, cat)
+			if pu.Source = src[cat]; pu.Source == "" {
+				pu.Source = src[plural.Other]
+			}
+			pu.Notes = append([]string{"plural form: " + string(cat)}, u.Notes...)
+			doc.Units = append(doc.Units, pu)
+		}
+	}
+	return doc
+}
+
+func pluralID(key string, cat plural.Category) string {
+	return key + "[" + string(cat) + "]"
+}
+
+// parsePluralID splits a unit id of the form 'key[category]'
+func parsePluralID(id string) (key string, cat plural.Category, ok bool) {
+	open := strings.LastIndex(id, "[")
+	if open < 0 || !strings.HasSuffix(id, "]") {
+		return id, "", false
+	}
+	cat = plural.Category(id[open+1 : len(id)-1])
+	if !cat.Valid() {
+		return id, "", false
+	}
+	return id[:open], cat, true
+}
+
+// importXLIFF reads translations from the XLIFF files matching pattern into the json asset for each file's
+// target language, <dir>/<lang>.json. An asset which does not exist is created, with langName, which is then
+// required, as its AA_NativeLangName; xlate needs the name of each language. An existing asset without one is
+// given langName too. Segments which are missing a translation, or whose translation is not final, are reported;
+// non-final translations are imported nevertheless. The source fingerprint of each message translated is removed,
+// as the translation is of the current text; -sync records the new one. Messages whose segments are all in the
+// initial state keep theirs.
+func importXLIFF(pattern, dir, langName string) {
+	files, err := fp.Glob(pattern)
+	if err != nil {
+		fatalf("-import-xliff: %s", err)
+	}
+	if len(files) == 0 {
+		fatalf("-import-xliff: no files match %s", pattern)
+	}
+	if langName != "" && len(files) > 1 {
+		fatalf("-import-xliff: -lang-name names a single language, but %d files match %s", len(files), pattern)
+	}
+	problems := 0
+	for _, fname := range files {
+		f, err := os.Open(fname)
+		if err != nil {
+			fatalf("-import-xliff: %s", err)
+		}
+		doc, err := xliff.Read(f)
+		f.Close()
+		if err != nil {
+			fatalf("-import-xliff: %s: %s", fname, err)
+		}
+		if doc.TargetLang == "" {
+			fatalf("-import-xliff: %s: no target language", fname)
+		}
+
+		asset := fp.Join(dir, strings.ToLower(doc.TargetLang)+".json")
+		c := catalog.New()
+		if data, err := os.ReadFile(asset); err == nil {
+			if c, err = catalog.Read(data); err != nil {
+				fatalf("-import-xliff: %s: %s", asset, err)
+			}
+		} else if !os.IsNotExist(err) {
+			fatalf("-import-xliff: %s", err)
+		} else if langName == "" {
+			fatalf("-import-xliff: %s does not exist; give -lang-name to create it", asset)
+		}
+		if c.Messages[catalog.NameKey] == "" && langName != "" {
+			c.Messages[catalog.NameKey] = langName
+		}
+
+		for _, u := range doc.Units {
+			switch {
+			case u.Target == "":
+				fmt.Fprintf(os.Stderr, "xtract: warning: %s: segment %s is missing a translation\n", fname, u.ID)
+				problems++
+				continue
+			case u.State != xliff.StateFinal:
+				fmt.Fprintf(os.Stderr, "xtract: warning: %s: segment %s is not final (%s)\n", fname, u.ID, u.State)
+				problems++
+			}
+			key, cat, isPlural := parsePluralID(u.ID)
+			if meta := c.Meta[key]; meta != nil && u.State != xliff.StateInitial {
+				meta.Source = ""
+			}
+			if isPlural {
+				if c.Plurals[key] == nil {
+					c.Plurals[key] = make(catalog.Plural)
+				}
+				c.Plurals[key][cat] = u.Target
+				delete(c.Messages, key)
+				continue
+			}
+			c.Messages[u.ID] = u.Target
+		}
+
+		var buf bytes.Buffer
+		if err := c.Write(&buf); err != nil {
+			fatalf("-import-xliff: %s: %s", asset, err)
+		}
+		if err := os.WriteFile(asset, buf.Bytes(), 0666); err != nil {
+			fatalf("-import-xliff: %s", err)
+		}
+		fmt.Printf("%s: imported into %s\n", fname, asset)
+	}
+	if *strict && problems > 0 {
+		fatalf("%d segment(s) missing or not final", problems)
+	}
+}
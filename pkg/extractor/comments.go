@@ -0,0 +1,149 @@
+package extractor
+
+import (
+	"go/ast"
+	"path/filepath"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/util"
+)
+
+// DefaultCommentPrefix marks comments near a call to the target function as being meant for translators
+const DefaultCommentPrefix = "TRANSLATORS:"
+
+// IDDirective is a comment giving the key of a message, in place of one derived from its const/var name or text.
+// It may be on the declaration of the const/var holding the message, immediately precede the call it is passed
+// to, or be on the same line:
+//
+//	//xtract:id login.title
+//	const LoginTitle = "Sign in"
+const IDDirective = "//xtract:id"
+
+// declDoc holds the comments on the declaration of a const/var
+type declDoc struct {
+	comments []string // for translators
+	id       string   // from IDDirective
+}
+
+// SetCommentPrefix sets the prefix marking comments near calls to the target function as being meant for
+// translators
+func (r *extractor) SetCommentPrefix(prefix string) {
+	r.commentPrefix = prefix
+}
+
+// callComments returns comments meant for translators which immediately precede the call, or which are on the
+// line it starts on. Only comments beginning with the comment prefix are considered.
+func (r *extractor) callComments(call *ast.CallExpr) []string {
+	if r.file == nil {
+		return nil
+	}
+	var comments []string
+	for _, cg := range r.nearCall(call) {
+		text := strings.TrimSpace(cg.Text())
+		if text != "" && strings.HasPrefix(text, r.commentPrefix) {
+			comments = append(comments, text)
+		}
+	}
+	return comments
+}
+
+// callID returns the key given by an IDDirective which immediately precedes the call, or is on the line it starts
+// on
+func (r *extractor) callID(call *ast.CallExpr) string {
+	if r.file == nil {
+		return ""
+	}
+	return directiveID(r.nearCall(call)...)
+}
+
+// nearCall returns the comment groups which end on the line before the call, or start on the line it starts on
+func (r *extractor) nearCall(call *ast.CallExpr) []*ast.CommentGroup {
+	line := r.position(call.Pos()).Line
+	var groups []*ast.CommentGroup
+	for _, cg := range r.file.Comments {
+		start, end := r.position(cg.Pos()).Line, r.position(cg.End()).Line
+		if end == line-1 || start == line {
+			groups = append(groups, cg)
+		}
+	}
+	return groups
+}
+
+// directiveID returns the key given by the first IDDirective in the comment groups
+func directiveID(groups ...*ast.CommentGroup) string {
+	for _, cg := range groups {
+		if cg == nil {
+			continue
+		}
+		for _, c := range cg.List {
+			if rest, ok := strings.CutPrefix(c.Text, IDDirective); ok && (rest == "" || rest[0] == ' ') {
+				return strings.TrimSpace(rest)
+			}
+		}
+	}
+	return ""
+}
+
+// declDoc returns the doc and line comments on the declaration of the const/var which a target function argument
+// refers to, if any
+func (r *extractor) declDoc(targetNode ast.Expr) declDoc {
+	var dir, path, name string
+	switch v := targetNode.(type) {
+	case *ast.Ident:
+		if d, ok := r.docs[v.Name]; ok {
+			return d
+		}
+		if v.Obj != nil {
+			return declDoc{} // declared in this file, but not at package level
+		}
+		dir, path, name = filepath.Dir(r.currentFile), ".", v.Name
+	case *ast.SelectorExpr:
+		pkg, ok := v.X.(*ast.Ident)
+		if !ok || pkg.Obj != nil {
+			return declDoc{}
+		}
+		if path, ok = r.imports[pkg.Name]; !ok {
+			return declDoc{}
+		}
+		dir, name = filepath.Dir(r.currentFile), v.Sel.Name
+	default:
+		return declDoc{}
+	}
+
+	files, err := r.pkgs.files(dir, path)
+	if err != nil {
+		util.Log.Printf("unable to read comments for %s: %s", name, err)
+		return declDoc{}
+	}
+	gen := newExtractor()
+	gen.pkgs = r.pkgs
+	for _, filename := range files {
+		astFile, err := r.pkgs.parse(filename)
+		if err != nil {
+			continue
+		}
+		gen.Load(astFile, filename)
+		if d, ok := gen.docs[name]; ok {
+			return d
+		}
+	}
+	return declDoc{}
+}
+
+// specDoc returns the comments attached to a const/var spec. For a spec alone in its declaration, the
+// declaration's doc comment is used if the spec has none.
+func specDoc(gd *ast.GenDecl, spec *ast.ValueSpec) declDoc {
+	var d declDoc
+	doc := spec.Doc
+	if doc == nil && len(gd.Specs) == 1 {
+		doc = gd.Doc
+	}
+	for _, cg := range []*ast.CommentGroup{doc, spec.Comment} {
+		// directives are omitted from the text
+		if text := strings.TrimSpace(cg.Text()); text != "" {
+			d.comments = append(d.comments, text)
+		}
+	}
+	d.id = directiveID(doc, spec.Comment)
+	return d
+}
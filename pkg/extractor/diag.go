@@ -0,0 +1,67 @@
+package extractor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/mpictor/go-xtract/pkg/util"
+)
+
+// Diagnostic describes a call to the target function whose message could not be extracted
+type Diagnostic struct {
+	Pos    token.Position // position of the argument, or of the call if it has none
+	Expr   string         // source text of the argument
+	Reason string         // why the message could not be extracted
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Expr, d.Reason)
+}
+
+// warn records a diagnostic for a target function argument which could not be extracted
+func (r *extractor) warn(node ast.Node, expr, reason string) {
+	d := Diagnostic{
+		Pos:    r.position(node.Pos()),
+		Expr:   expr,
+		Reason: reason,
+	}
+	util.Log.Printf("cannot extract: %s", d)
+	r.diagnostics = append(r.diagnostics, d)
+}
+
+// warnNonConst records a diagnostic for an argument which is not a constant string expression. Nothing is
+// recorded if extraction failed due to an unresolved reference, as that is already reported by Err.
+func (r *extractor) warnNonConst(arg ast.Expr, unresolved int) {
+	if len(r.unresolved) > unresolved {
+		return
+	}
+	r.warn(arg, types.ExprString(arg), NonConstReason(arg))
+}
+
+// NonConstReason explains why arg, which is not a constant string expression, cannot be extracted
+func NonConstReason(arg ast.Expr) string {
+	var reason string
+	switch e := ast.Unparen(arg).(type) {
+	case *ast.CallExpr:
+		reason = "result of a function call is not constant"
+	case *ast.Ident, *ast.SelectorExpr:
+		reason = "not a const, or a var initialized with a constant string expression"
+	case *ast.BinaryExpr:
+		reason = "operand is not a constant string expression"
+		if e.Op != token.ADD {
+			reason = "operator " + e.Op.String() + " does not produce a constant string"
+		}
+	case *ast.BasicLit:
+		reason = "not a string literal"
+	default:
+		reason = "not a constant string expression"
+	}
+	return reason
+}
+
+// Diagnostics returns the calls to the target function which could not be extracted, in the order found
+func (r extractor) Diagnostics() []Diagnostic {
+	return r.diagnostics
+}
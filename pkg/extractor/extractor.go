@@ -1,13 +1,12 @@
 package extractor
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
-	"io/ioutil"
-	"log"
-	"os"
+	"go/types"
 	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
 
 	"github.com/mpictor/go-xtract/pkg/util"
@@ -23,15 +22,34 @@ type Extractor interface {
 	ast.Visitor
 	Load(*ast.File, string)
 
+	// Strings returns the text of the extracted messages, sorted and without duplicates.
 	Strings() []string
+	// Vars returns the extracted messages, sorted by text and then context.
 	Vars() VarList
+
+	// Err reports references passed to the target function which could not be resolved.
+	Err() error
+
+	// Diagnostics lists calls to the target function which could not be extracted, such as those with
+	// non-constant arguments.
+	Diagnostics() []Diagnostic
+
+	// SetCommentPrefix sets the prefix marking comments immediately preceding a call to the target function, or on
+	// the same line, as meant for translators. With an empty prefix, all such comments are used. Defaults to
+	// DefaultCommentPrefix. Comments on the declarations of consts/vars passed to the target are always used.
+	SetCommentPrefix(prefix string)
 }
 
-// New creates a new Extractor
+// New creates a new Extractor for the given function, whose first argument is the message
 func New(targetFuncPackage, targetFuncName string) Extractor {
+	return NewTargets(NewTarget(targetFuncPackage, targetFuncName))
+}
+
+// NewTargets creates a new Extractor for calls to any of the given targets. Method targets are ignored, as
+// matching them requires type information; use NewTypedTargets instead.
+func NewTargets(targets ...Target) Extractor {
 	t := newExtractor()
-	t.tfPackage = targetFuncPackage
-	t.tfName = targetFuncName
+	t.targets = targets
 	return t
 }
 
@@ -44,30 +62,70 @@ func NewFromFunction(targetFunc interface{}) Extractor {
 
 func newExtractor() *extractor {
 	return &extractor{
-		strings:   make(map[string]bool),
-		vars:      make(map[string][]string),
-		imports:   make(map[string]string),
-		symbols:   make(map[string]string),
-		tfPackage: "fmt",
-		tfName:    "Sprintf",
+		strings:       make(map[string]bool),
+		refs:          make(map[string][]Ref),
+		comments:      make(map[string][]string),
+		plurals:       make(map[string]string),
+		docs:          make(map[string]declDoc),
+		ids:           make(map[string]string),
+		commentPrefix: DefaultCommentPrefix,
+		vars:          make(map[string][]VarDecl),
+		imports:       make(map[string]string),
+		symbols:       make(map[string]ast.Expr),
+		decls:         make(map[string]bool),
+		pkgs:          newPackageCache(),
+		position:      util.Position,
+		targets:       []Target{NewTarget("fmt", "Sprintf")},
 	}
 }
 
 // implements the ast.Visitor interface
 type extractor struct {
-	// target function information
-	tfPackage string
-	tfName    string
+	// target functions
+	targets []Target
 
 	// internal file information
 	currentFile string
+	pkgPath     string // of currentFile, once known
 	imports     map[string]string
-	symbols     map[string]string
+	symbols     map[string]ast.Expr
+	decls       map[string]bool
+	funcs       []*ast.FuncDecl
+	docs        map[string]declDoc
+	file        *ast.File
+
+	// maps positions of the nodes being visited
+	position func(token.Pos) token.Position
+
+	// go files of packages consulted when resolving symbols
+	pkgs *packageCache
+
+	// references which could not be resolved
+	unresolved []string
+
+	// calls which could not be extracted
+	diagnostics []Diagnostic
+
+	// nesting of symbol resolution, to guard against cycles
+	depth int
 
 	// extracted artifacts
 	strings map[string]bool
-	//map from str to names of vars containing it
-	vars map[string][]string
+	// maps below are keyed by str, preceded by its context and \x04 if it has one
+
+	//map from str to the vars containing it
+	vars map[string][]VarDecl
+	//map from str to the key given by an xtract:id directive
+	ids map[string]string
+	//map from str to the calls it was passed to
+	refs map[string][]Ref
+	//map from str to comments for translators
+	comments map[string][]string
+	//map from str to its plural form, for strs passed to plural funcs
+	plurals map[string]string
+
+	// prefix marking comments for translators near calls
+	commentPrefix string
 }
 
 // Visit visit a node in the go file's AST
@@ -93,105 +151,247 @@ func (r *extractor) Visit(node ast.Node) ast.Visitor {
 		pkgName := pkg.Name
 		funcName := function.Sel.Name
 
-		var err error
-		if funcName != r.tfName || r.imports[pkgName] != r.tfPackage {
+		target := r.target(r.imports[pkgName], funcName)
+		if target == nil {
 			break // wrong function
 		}
+		if !r.extractCall(call, target, r.evalString) {
+			break // failed to extract
+		}
 
-		if len(call.Args) == 0 {
-			log.Printf("skipping niladic call to target function")
-			break //skip
+		// stop traversing this branch of the tree
+		return nil
+	}
+
+	return r
+}
+
+// target returns the package-level function target with the given package and name, or nil. Method targets
+// require type information, so are only matched by the typed extractor.
+func (r *extractor) target(pkg, name string) *Target {
+	for i := range r.targets {
+		if !r.targets[i].IsMethod() && r.targets[i].Package == pkg && r.targets[i].Name == name {
+			return &r.targets[i]
 		}
+	}
+	return nil
+}
+
+// extractCall records the message passed to a call to the target function, using eval to determine the value of
+// the message argument. Returns false if the message could not be extracted.
+func (r *extractor) extractCall(call *ast.CallExpr, target *Target, eval func(ast.Expr) (string, bool)) bool {
+	return r.extractArgs(call, call.Args, target, eval)
+}
+
+// extractArgs is like extractCall, for calls where the target's arguments are not those of the call itself, such
+// as method expressions whose first argument is the receiver
+func (r *extractor) extractArgs(call *ast.CallExpr, args []ast.Expr, target *Target,
+	eval func(ast.Expr) (string, bool)) bool {
+	if len(args) == 0 {
+		r.warn(call, types.ExprString(call), "no arguments")
+		return false
+	}
+	if len(args) <= target.maxArg() {
+		r.warn(call, types.ExprString(call), fmt.Sprintf("expected at least %d arguments for %s",
+			target.maxArg()+1, target))
+		return false
+	}
 
-		targetNode := call.Args[0]
-		log.Printf("string key: (%T) %+v", targetNode, targetNode)
-
-		var value string
-		switch targetNode.(type) {
-		case *ast.BasicLit:
-			value, ok = r.extractStringLiteral(targetNode)
-		case *ast.Ident:
-			value, ok = r.extractLocalConstVar(targetNode)
-		case *ast.SelectorExpr:
-			value, ok = r.extractImportedConstVar(targetNode)
+	targetNode := args[target.Msg]
+	util.Log.Printf("string key: (%T) %+v", targetNode, targetNode)
+
+	unresolved := len(r.unresolved)
+	value, ok := eval(targetNode)
+	if !ok {
+		r.warnNonConst(targetNode, unresolved)
+		return false
+	}
+	var ctx, plural string
+	if target.Ctx >= 0 {
+		ctxNode := args[target.Ctx]
+		if ctx, ok = eval(ctxNode); !ok {
+			r.warnNonConst(ctxNode, unresolved)
+			return false
 		}
-		if !ok {
-			break // failed to extract
+	}
+	if target.Plural >= 0 {
+		pluralNode := args[target.Plural]
+		if plural, ok = eval(pluralNode); !ok {
+			r.warnNonConst(pluralNode, unresolved)
+			return false
 		}
+	}
+	r.record(ctx, value, plural, targetNode, call)
+	return true
+}
 
-		// unquote the string literal
-		value, err = strconv.Unquote(value)
-		if err == nil && value != "" {
-			if _, ok := r.strings[value]; !ok {
-				log.Printf("recorded new string: '%s'", value)
-				r.strings[value] = true
-			}
-			r.storeVarName(value, targetNode)
+// record stores an extracted string, its context and its plural form, along with the name of the const/var it
+// was read from if any and the position of the call it was passed to
+func (r *extractor) record(ctx, value, plural string, targetNode ast.Expr, call *ast.CallExpr) {
+	if value == "" {
+		return
+	}
+	key := msgKey(ctx, value)
+	if _, ok := r.strings[key]; !ok {
+		util.Log.Printf("recorded new string: '%s' (context '%s')", value, ctx)
+		r.strings[key] = true
+	}
+	if plural != "" {
+		if prev, ok := r.plurals[key]; ok && prev != plural {
+			// a catalog holds one message per string and context
+			r.warn(call, types.ExprString(call), fmt.Sprintf("plural form %q differs from %q used elsewhere", plural, prev))
+		} else {
+			r.plurals[key] = plural
 		}
+	}
+	r.storeVarName(key, targetNode)
+	doc := r.declDoc(targetNode)
+	r.storeComments(key, doc.comments)
+	r.storeComments(key, r.callComments(call))
+	r.storeID(call, key, doc.id)
+	r.storeID(call, key, r.callID(call))
+	pos := r.position(call.Pos())
+	r.refs[key] = append(r.refs[key], Ref{
+		File:   pos.Filename,
+		Line:   pos.Line,
+		Column: pos.Column,
+		Func:   r.enclosingFunc(call.Pos()),
+	})
+}
 
-		// stop traversing this branch of the tree
-		return nil
+// enclosingFunc returns the name of the function declaration containing pos, or "" if there is none
+func (r *extractor) enclosingFunc(pos token.Pos) string {
+	for _, fn := range r.funcs {
+		if fn.Pos() <= pos && pos < fn.End() {
+			return funcName(fn)
+		}
+	}
+	return ""
+}
+
+// funcName returns the name of a function declaration, qualified by its receiver type for methods: Func,
+// Type.Method or (*Type).Method
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	ptr := false
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv, ptr = star.X, true
+	}
+	switch r := recv.(type) {
+	case *ast.IndexExpr: // generic receiver
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
 	}
+	name := types.ExprString(recv)
+	if ptr {
+		return "(*" + name + ")." + fn.Name.Name
+	}
+	return name + "." + fn.Name.Name
+}
 
-	return r
+func (r extractor) storeComments(value string, comments []string) {
+	for _, c := range comments {
+		if !contains(r.comments[value], c) {
+			r.comments[value] = append(r.comments[value], c)
+		}
+	}
 }
 
-func (r extractor) storeVarName(value string, targetNode ast.Expr) {
-	var varName string
+// storeID records the key given to a string by an xtract:id directive, warning if it was given another
+func (r *extractor) storeID(call *ast.CallExpr, value, id string) {
+	if id == "" {
+		return
+	}
+	if prev, ok := r.ids[value]; ok && prev != id {
+		r.warn(call, types.ExprString(call), fmt.Sprintf("id %q differs from %q given elsewhere", id, prev))
+		return
+	}
+	r.ids[value] = id
+}
+
+func (r *extractor) storeVarName(value string, targetNode ast.Expr) {
+	var decl VarDecl
 	switch v := targetNode.(type) {
 	case *ast.Ident:
-		varName = v.Name
+		decl.Name = v.Name
+		if r.file != nil {
+			decl.PkgName = r.file.Name.Name
+			decl.PkgPath = r.currentPkgPath()
+		}
 	case *ast.SelectorExpr:
-		varName = v.Sel.Name
+		decl.Name = v.Sel.Name
+		if pkg, ok := v.X.(*ast.Ident); ok && pkg.Obj == nil {
+			decl.PkgName = r.packageName(pkg.Name)
+			decl.PkgPath = r.imports[pkg.Name]
+		}
 	default:
 		return
 	}
-	if len(varName) == 0 {
-		log.Printf("unable to determine varname for %q, %v", value, targetNode)
+	if len(decl.Name) == 0 {
+		util.Log.Printf("unable to determine varname for %q, %v", value, targetNode)
 		return
 	}
-	vals := r.vars[value]
-	for _, v := range vals {
-		if v == varName {
+	decls := r.vars[value]
+	for _, d := range decls {
+		if d == decl {
 			//duplicate
 			return
 		}
 	}
-	r.vars[value] = append(vals, varName)
+	r.vars[value] = append(decls, decl)
 }
 
-func (r extractor) extractStringLiteral(node ast.Node) (value string, ok bool) {
-	literal := node.(*ast.BasicLit)
-	if literal == nil || literal.Kind != token.STRING {
-		return "", false
+// currentPkgPath returns the import path of the current file's package, if it can be determined
+func (r *extractor) currentPkgPath() string {
+	if r.pkgPath == "" {
+		r.pkgPath = r.pkgs.importPath(filepath.Dir(r.currentFile))
+	}
+	return r.pkgPath
+}
+
+// packageName returns the name of the package imported as pkg in the current file
+func (r *extractor) packageName(pkg string) string {
+	path, ok := r.imports[pkg]
+	if !ok {
+		return ""
 	}
-	return literal.Value, true
+	name, err := r.pkgs.name(filepath.Dir(r.currentFile), path)
+	if err != nil {
+		util.Log.Printf("unable to determine the name of package %s: %s", path, err)
+		return pkg
+	}
+	return name
 }
 
-func (r extractor) extractLocalConstVar(node ast.Node) (value string, ok bool) {
+func (r *extractor) extractLocalConstVar(node ast.Node) (value string, ok bool) {
 	ident := node.(*ast.Ident)
 	symbol := ident.Name
 
-	value, ok = r.symbols[symbol]
-	if ok {
-		return value, true
+	if expr, ok := r.symbols[symbol]; ok {
+		return r.evalString(expr)
+	}
+	if ident.Obj != nil || types.Universe.Lookup(symbol) != nil {
+		// declared in this file but not as a string const/var, or a predeclared identifier such as nil
+		util.Log.Printf("symbol %s is not a string const/var", symbol)
+		return "", false
 	}
 
 	// symbol not defined in current file. need to scan other files in the package.
-	currentImportPath := strings.TrimPrefix(
-		filepath.Dir(r.currentFile),
-		filepath.Join(os.Getenv("GOPATH"), "src"),
-	)
-	value, err := r.resolveSymbol(currentImportPath, symbol)
+	dir := filepath.Dir(r.currentFile)
+	value, err := r.resolveSymbol(dir, ".", symbol)
 	if err != nil {
-		log.Printf("unable to resolve symbol %s: %s", symbol, err.Error())
+		r.unresolve(ident, symbol, err)
 		return "", false
 	}
-	log.Printf("successfully resolved local symbol %s.%s = %s", currentImportPath, symbol, value)
+	util.Log.Printf("successfully resolved local symbol %s.%s = %s", dir, symbol, value)
 	return value, true
 }
 
-func (r extractor) extractImportedConstVar(node ast.Node) (value string, ok bool) {
+func (r *extractor) extractImportedConstVar(node ast.Node) (value string, ok bool) {
 	function := node.(*ast.SelectorExpr)
 	if function == nil {
 		return "", false
@@ -205,20 +405,41 @@ func (r extractor) extractImportedConstVar(node ast.Node) (value string, ok bool
 	pkgName := pkg.Name
 	symbol := function.Sel.Name
 
-	value, err := r.resolveSymbol(r.imports[pkgName], symbol)
+	path, ok := r.imports[pkgName]
+	if !ok || pkg.Obj != nil {
+		// selector on a local variable or struct, not on a package
+		util.Log.Printf("%s.%s is not a package-level symbol", pkgName, symbol)
+		return "", false
+	}
+
+	value, err := r.resolveSymbol(filepath.Dir(r.currentFile), path, symbol)
 	if err != nil {
-		log.Printf("unable to resolve symbol %s.%s: %s", pkgName, symbol, err.Error())
+		r.unresolve(function, pkgName+"."+symbol, err)
 		return "", false
 	}
-	log.Printf("successfully resolved %s.%s = %s", pkgName, symbol, value)
+	util.Log.Printf("successfully resolved %s.%s = %s", pkgName, symbol, value)
 	return value, true
 }
 
+// unresolve records a reference which could not be resolved. Symbols which are
+// declared but not as constant string expressions are not considered unresolved.
+func (r *extractor) unresolve(node ast.Node, ref string, err error) {
+	if err == errNotConst {
+		util.Log.Printf("symbol %s is not a string const/var", ref)
+		return
+	}
+	util.Log.Printf("unable to resolve symbol %s: %s", ref, err.Error())
+	pos := r.position(node.Pos())
+	r.unresolved = append(r.unresolved, fmt.Sprintf("%s: %s: %s", pos, ref, err))
+}
+
 // Load loads import, const, and variable declarations from the provide go file AST
 func (r *extractor) Load(file *ast.File, filename string) {
 	r.currentFile = filename
+	r.file = file
+	r.pkgPath = ""
 
-	log.Printf("parsing import declarations for file: %s", filename)
+	util.Log.Printf("parsing import declarations for file: %s", filename)
 	r.imports = make(map[string]string, len(file.Imports))
 	for _, importSpec := range file.Imports {
 		path := strings.Trim(importSpec.Path.Value, "\"")
@@ -228,15 +449,25 @@ func (r *extractor) Load(file *ast.File, filename string) {
 			name = importSpec.Name.Name
 		}
 
-		log.Printf("recorded named import %s -> %s\n", name, path)
+		util.Log.Printf("recorded named import %s -> %s\n", name, path)
 		r.imports[name] = path
 	}
 
-	log.Printf("parsing global declarations for file: %s", filename)
-	r.symbols = make(map[string]string, len(file.Decls))
+	util.Log.Printf("parsing global declarations for file: %s", filename)
+	r.symbols = make(map[string]ast.Expr, len(file.Decls))
+	r.funcs = nil
+	r.docs = make(map[string]declDoc)
+	r.decls = make(map[string]bool, len(file.Scope.Objects))
+	for name := range file.Scope.Objects {
+		// all top-level consts, vars, types and funcs
+		r.decls[name] = true
+	}
 	for _, decl := range file.Decls {
-		log.Printf("declaration: (%T) %+v", decl, decl)
+		util.Log.Printf("declaration: (%T) %+v", decl, decl)
 
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			r.funcs = append(r.funcs, fn)
+		}
 		gd, ok := decl.(*ast.GenDecl)
 		if !ok {
 			continue // skip
@@ -247,31 +478,40 @@ func (r *extractor) Load(file *ast.File, filename string) {
 			continue // skip
 		}
 
+		var prev []ast.Expr
+
 		for _, spec := range gd.Specs {
-			log.Printf("  %s spec: (%T) %+v", gd.Tok, spec, spec)
+			util.Log.Printf("  %s spec: (%T) %+v", gd.Tok, spec, spec)
 			valueSpec, ok := spec.(*ast.ValueSpec)
 			if !ok {
 				// not a constant or var assignment
 				continue
 			}
 
-			for ix := range valueSpec.Values {
-				name := valueSpec.Names[ix].Name
-				valueExpr := valueSpec.Values[ix]
-				log.Printf("    %s spec: %s = (%T) %+v", gd.Tok, name, valueExpr, valueExpr)
+			values := valueSpec.Values
+			if len(values) == 0 && gd.Tok == token.CONST && valueSpec.Type == nil {
+				// const spec implicitly repeating the previous expression list
+				values = prev
+			}
+			prev = values
 
-				value, ok := valueExpr.(*ast.BasicLit)
-				if !ok {
-					// not a string literal
-					continue // skip
+			for ix := range values {
+				if ix >= len(valueSpec.Names) {
+					break
 				}
+				name := valueSpec.Names[ix].Name
+				valueExpr := values[ix]
+				if doc := specDoc(gd, valueSpec); len(doc.comments) > 0 || doc.id != "" {
+					r.docs[name] = doc
+				}
+				util.Log.Printf("    %s spec: %s = (%T) %+v", gd.Tok, name, valueExpr, valueExpr)
 
-				if value.Value == "" {
+				if lit, ok := valueExpr.(*ast.BasicLit); ok && lit.Value == "" {
 					// empty string
 					continue // skip
 				}
-				log.Printf("    recorded symbol %s.%s = '%s'\n", file.Name, name, value.Value)
-				r.symbols[name] = value.Value
+				util.Log.Printf("    recorded symbol %s.%s = %+v\n", file.Name, name, valueExpr)
+				r.symbols[name] = valueExpr
 			}
 		}
 
@@ -280,71 +520,166 @@ func (r *extractor) Load(file *ast.File, filename string) {
 
 func (r extractor) Strings() []string {
 	results := make([]string, 0, len(r.strings))
-	for s := range r.strings {
-		results = append(results, s)
+	seen := make(map[string]bool, len(r.strings))
+	for key := range r.strings {
+		_, s := splitMsgKey(key)
+		if !seen[s] {
+			seen[s] = true
+			results = append(results, s)
+		}
 	}
+	sort.Strings(results)
 	return results
 }
 
+// varNames returns the names of decls, without duplicates
+func varNames(decls []VarDecl) []string {
+	var names []string
+	for _, d := range decls {
+		if !contains(names, d.Name) {
+			names = append(names, d.Name)
+		}
+	}
+	return names
+}
+
+// msgKey identifies a message by its context and text, as in gettext's .mo files
+func msgKey(ctx, value string) string {
+	if ctx == "" {
+		return value
+	}
+	return ctx + "\x04" + value
+}
+
+func splitMsgKey(key string) (ctx, value string) {
+	if i := strings.IndexByte(key, '\x04'); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+// ValVars is an extracted string, along with its context, its plural form, the names of the consts/vars holding
+// it, the places it occurs, and comments for translators from its declaration or calls. Identical strings with
+// different contexts are distinct messages.
 type ValVars struct {
-	Val  string
-	Vars []string
+	Val      string
+	Ctx      string
+	Plural   string // empty unless the string was passed to a plural func
+	Vars     []string
+	Decls    []VarDecl // the consts/vars named in Vars, with their packages
+	ID       string    // key given by an xtract:id directive, if any
+	Refs     []Ref
+	Comments []string
+}
+
+// VarDecl is a const/var holding a string passed to the target function
+type VarDecl struct {
+	Name    string
+	PkgName string // name of the package declaring it, if known
+	PkgPath string // import path of the package declaring it, if known
 }
 
 type VarList []ValVars
 
+// Ref is a call to the target function which a string was passed to
+type Ref struct {
+	File   string
+	Line   int
+	Column int
+	Func   string // enclosing function, if any; methods are qualified by their receiver type
+}
+
+// String returns the reference as file:line, as in gettext reference comments
+func (r Ref) String() string {
+	return fmt.Sprintf("%s:%d", r.File, r.Line)
+}
+
 func (r extractor) Vars() (list VarList) {
-	for val := range r.strings {
+	for key := range r.strings {
+		ctx, val := splitMsgKey(key)
 		list = append(list, ValVars{
-			Val:  val,
-			Vars: r.vars[val],
+			Val:      val,
+			Ctx:      ctx,
+			Plural:   r.plurals[key],
+			Vars:     varNames(r.vars[key]),
+			Decls:    r.vars[key],
+			ID:       r.ids[key],
+			Refs:     r.refs[key],
+			Comments: r.comments[key],
 		})
 	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Val != list[j].Val {
+			return list[i].Val < list[j].Val
+		}
+		return list[i].Ctx < list[j].Ctx
+	})
 	return list
 }
 
-// resolve value of the given symbol in the provided package. must be declared in a 'const' or 'var' block.
-func (r *extractor) resolveSymbol(path, name string) (string, error) {
-	goSrc := filepath.Join(os.Getenv("GOPATH"), "src")
-	if !strings.HasPrefix(path, goSrc) {
-		path = filepath.Join(os.Getenv("GOPATH"), "src", path)
+// Err returns an UnresolvedError if any references could not be resolved.
+func (r extractor) Err() error {
+	if len(r.unresolved) == 0 {
+		return nil
 	}
+	return UnresolvedError(r.unresolved)
+}
+
+// UnresolvedError lists references passed to the target function whose
+// declarations could not be found, one per line.
+type UnresolvedError []string
+
+func (e UnresolvedError) Error() string {
+	return fmt.Sprintf("unable to resolve %d reference(s):\n\t%s", len(e), strings.Join(e, "\n\t"))
+}
 
-	log.Printf("attempting to resolve symbol %s in %s", name, path)
-	files, err := ioutil.ReadDir(path)
+// returned by resolveSymbol when the symbol exists but its value is not a constant string expression
+var errNotConst = errors.New("declared, but not as a constant string expression")
+
+// resolve value of the given symbol in the package with the provided import path, as seen from dir. must be
+// declared in a 'const' or 'var' block, with a constant string expression as its value.
+func (r *extractor) resolveSymbol(dir, path, name string) (string, error) {
+	util.Log.Printf("attempting to resolve symbol %s in %s", name, path)
+	files, err := r.pkgs.files(dir, path)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to read dir for imported package")
+		return "", err
 	}
 
 	// create separate generator instance to avoid overwriting file/translation data
 	gen := newExtractor()
+	gen.pkgs = r.pkgs
 
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-
-		filename := filepath.Join(path, file.Name())
+	declared := false
+	for _, filename := range files {
 		if filename == r.currentFile {
 			// should not load the same file twice
 			continue
 		}
-		log.Printf("scanning file %s for symbol %s...", filename, name)
+		util.Log.Printf("scanning file %s for symbol %s...", filename, name)
 
-		astFile, err := util.ParseGoFile(filename)
+		astFile, err := r.pkgs.parse(filename)
 		if err != nil {
-			log.Printf("failed to parse Go file: %s", err.Error())
+			util.Log.Printf("failed to parse Go file: %s", err.Error())
+			continue
 		}
 
 		// load globals
 		gen.Load(astFile, filename)
 
 		// check globals against provided symbol
-		for symbol, value := range gen.symbols {
-			if symbol == name {
-				return value, nil
+		if expr, ok := gen.symbols[name]; ok {
+			gen.depth = r.depth + 1
+			value, ok := gen.evalString(expr)
+			r.unresolved = append(r.unresolved, gen.unresolved...)
+			if !ok {
+				return "", errNotConst
 			}
+			return value, nil
 		}
+		declared = declared || gen.decls[name]
+	}
+	if declared {
+		return "", errNotConst
 	}
 
 	return "", errors.New("desired const/variable declaration not found")
@@ -2,25 +2,38 @@ package extractor
 
 import (
 	"go/ast"
-	"log"
 
 	"github.com/mpictor/go-xtract/pkg/util"
+	"github.com/pkg/errors"
 )
 
-// ProcessFiles process each file with the provided Extractor
-func ProcessFiles(extractor Extractor, files ...string) {
+// ProcessFiles process each file with the provided Extractor. Returns an error if any file fails to parse or if
+// references passed to the target function could not be resolved.
+func ProcessFiles(extractor Extractor, files ...string) error {
+	if typed, ok := extractor.(*typedExtractor); ok {
+		// files must be loaded along with their packages' type information
+		return typed.processFiles(files)
+	}
 	for _, filename := range files {
-		log.Printf("processing file %s", filename)
-
-		file, err := util.ParseGoFile(filename)
-		if err != nil {
-			log.Fatalf("failed to parse file as AST: %s", err.Error())
+		if err := processFile(extractor, filename); err != nil {
+			return err
 		}
+	}
+	return extractor.Err()
+}
 
-		// load in file imports & variable declarations
-		extractor.Load(file, filename)
+func processFile(extractor Extractor, filename string) error {
+	util.Log.Printf("processing file %s", filename)
 
-		// walk the target file for translation texts
-		ast.Walk(extractor, file)
+	file, err := util.ParseGoFile(filename)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse file as AST")
 	}
+
+	// load in file imports & variable declarations
+	extractor.Load(file, filename)
+
+	// walk the target file for translation texts
+	ast.Walk(extractor, file)
+	return nil
 }
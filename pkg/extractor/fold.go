@@ -0,0 +1,55 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	"github.com/mpictor/go-xtract/pkg/util"
+)
+
+// limit on nested symbol resolution, in case of cyclic declarations
+const maxDepth = 32
+
+// evalString folds a constant string expression into its value. Supported are string literals, parenthesized
+// expressions, concatenations, conversions to string, and consts or vars whose values are themselves constant
+// string expressions - including those declared in other files and packages. Typed string consts are handled
+// like untyped ones. Returns false if the expression is not constant, or if a reference could not be resolved;
+// unresolved references are recorded.
+func (r *extractor) evalString(expr ast.Expr) (string, bool) {
+	if r.depth > maxDepth {
+		util.Log.Printf("symbol resolution nested too deeply at %s", r.position(expr.Pos()))
+		return "", false
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(e.Value)
+		return value, err == nil
+	case *ast.ParenExpr:
+		return r.evalString(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := r.evalString(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := r.evalString(e.Y)
+		return x + y, ok
+	case *ast.CallExpr:
+		// conversion such as string("...")
+		if fn, ok := e.Fun.(*ast.Ident); ok && fn.Name == "string" && fn.Obj == nil && len(e.Args) == 1 {
+			return r.evalString(e.Args[0])
+		}
+	case *ast.Ident:
+		return r.extractLocalConstVar(e)
+	case *ast.SelectorExpr:
+		return r.extractImportedConstVar(e)
+	}
+	util.Log.Printf("not a constant string expression: (%T) %+v", expr, expr)
+	return "", false
+}
@@ -0,0 +1,120 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/mpictor/go-xtract/pkg/util"
+)
+
+// Matcher finds calls to targets using type information, as the extractors created by NewTypedTargets do. It is
+// exported for tools which have type information of their own, such as analyzers.
+type Matcher struct {
+	targets []Target
+
+	// target functions, and variables known to hold them
+	objects map[types.Object]*Target
+}
+
+// NewMatcher creates a Matcher for calls to any of the given targets. Their packages must be added with
+// AddPackage before calls to them are matched.
+func NewMatcher(targets ...Target) *Matcher {
+	return &Matcher{targets: targets, objects: make(map[types.Object]*Target)}
+}
+
+// AddPackage looks up the targets declared in pkg, if any
+func (m *Matcher) AddPackage(pkg *types.Package) {
+	for i, t := range m.targets {
+		if t.Package != pkg.Path() {
+			continue
+		}
+		if obj := lookupTarget(pkg, t); obj != nil {
+			m.objects[obj] = &m.targets[i]
+		} else {
+			util.Log.Printf("target %s not found in package %s", t, pkg.Path())
+		}
+	}
+}
+
+// Found reports whether any target has been found by AddPackage
+func (m *Matcher) Found() bool { return len(m.objects) > 0 }
+
+// AddAliases records variables in files, type-checked with info, which are assigned a target function, so that
+// calls through them are matched. It reports whether any were found; as aliases may be assigned to others, such
+// as g := f, callers should repeat until none are.
+func (m *Matcher) AddAliases(info *types.Info, files []*ast.File) bool {
+	if len(m.objects) == 0 || info == nil {
+		return false
+	}
+	found := false
+	check := func(lhs *ast.Ident, rhs ast.Expr) {
+		if lhs == nil {
+			return
+		}
+		target := m.targetOf(info, rhs)
+		if target == nil {
+			return
+		}
+		obj := info.ObjectOf(lhs)
+		if obj != nil && m.objects[obj] == nil {
+			util.Log.Printf("%s holds target function %s", lhs.Name, target)
+			m.objects[obj] = target
+			found = true
+		}
+	}
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == len(n.Rhs) {
+					for i := range n.Lhs {
+						lhs, _ := n.Lhs[i].(*ast.Ident)
+						check(lhs, n.Rhs[i])
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) == len(n.Values) {
+					for i := range n.Names {
+						check(n.Names[i], n.Values[i])
+					}
+				}
+			}
+			return true
+		})
+	}
+	return found
+}
+
+// targetOf returns the target function which expr refers to, directly or through a variable holding it
+func (m *Matcher) targetOf(info *types.Info, expr ast.Expr) *Target {
+	var obj types.Object
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		obj = info.Uses[e]
+	case *ast.SelectorExpr:
+		obj = info.Uses[e.Sel]
+	}
+	if obj == nil {
+		return nil
+	}
+	return m.objects[obj]
+}
+
+// Match returns the target called by call, type-checked with info, and the arguments passed to it; for a method
+// expression such as (*T).Method(recv, args...), the receiver is not one of them. It returns nil if call is not
+// to a target.
+func (m *Matcher) Match(info *types.Info, call *ast.CallExpr) (*Target, []ast.Expr) {
+	target := m.objects[typeutil.Callee(info, call)]
+	if target == nil {
+		return nil, nil
+	}
+	args := call.Args
+	if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+		if s := info.Selections[sel]; s != nil && s.Kind() == types.MethodExpr && len(args) > 0 {
+			args = args[1:]
+		}
+	}
+	return target, args
+}
@@ -0,0 +1,145 @@
+package extractor
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// packageCache maps import paths to the Go files making up each package. Lookups go through go/packages so that
+// module-mode projects, replace directives, the module cache and vendored dependencies are all honoured. Each
+// lookup runs 'go list', so results are cached.
+type packageCache struct {
+	byKey  map[string]*packages.Package
+	parsed map[string]*ast.File
+	// maps directories to the import paths of their packages
+	paths map[string]string
+}
+
+func newPackageCache() *packageCache {
+	return &packageCache{
+		byKey:  make(map[string]*packages.Package),
+		parsed: make(map[string]*ast.File),
+		paths:  make(map[string]string),
+	}
+}
+
+// files returns the Go files of the package with the given import path, resolved relative to dir. Use "." for the
+// package in dir itself. Test files are not included.
+func (c *packageCache) files(dir, path string) ([]string, error) {
+	pkg, err := c.load(dir, path)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.GoFiles, nil
+}
+
+// name returns the name of the package with the given import path, resolved relative to dir
+func (c *packageCache) name(dir, path string) (string, error) {
+	pkg, err := c.load(dir, path)
+	if err != nil {
+		return "", err
+	}
+	return pkg.Name, nil
+}
+
+func (c *packageCache) load(dir, path string) (*packages.Package, error) {
+	key := dir + "\x00" + path
+	if pkg, ok := c.byKey[key]; ok {
+		return pkg, nil
+	}
+
+	util.Log.Printf("loading package %s from %s", path, dir)
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load package %s", path)
+	}
+	if len(pkgs) != 1 {
+		return nil, errors.Errorf("expected 1 package for %s, found %d", path, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.GoFiles) == 0 && len(pkg.Errors) > 0 {
+		return nil, errors.Wrapf(pkg.Errors[0], "failed to load package %s", path)
+	}
+
+	c.byKey[key] = pkg
+	return pkg, nil
+}
+
+// parse parses a Go file, reusing the result if it was parsed already
+func (c *packageCache) parse(filename string) (*ast.File, error) {
+	if file, ok := c.parsed[filename]; ok {
+		return file, nil
+	}
+	file, err := util.ParseGoFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c.parsed[filename] = file
+	return file, nil
+}
+
+// importPath returns the import path of the package in dir. It is derived from the module path in the nearest
+// go.mod if there is one, which is much faster than loading the package.
+func (c *packageCache) importPath(dir string) string {
+	if path, ok := c.paths[dir]; ok {
+		return path
+	}
+	path := moduleImportPath(dir)
+	if path == "" {
+		if pkg, err := c.load(dir, "."); err == nil {
+			path = pkg.PkgPath
+		} else {
+			util.Log.Printf("unable to determine the import path of %s: %s", dir, err)
+		}
+	}
+	c.paths[dir] = path
+	return path
+}
+
+// moduleImportPath returns the import path of the package in dir within the module containing it, or "" if it is
+// not in a module
+func moduleImportPath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if data, err := os.ReadFile(filepath.Join(d, "go.mod")); err == nil {
+			mod := modulePath(data)
+			if mod == "" {
+				return ""
+			}
+			rel, err := filepath.Rel(d, abs)
+			if err != nil {
+				return ""
+			}
+			if rel == "." {
+				return mod
+			}
+			return mod + "/" + filepath.ToSlash(rel)
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// modulePath returns the path in the module directive of a go.mod file
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+	return ""
+}
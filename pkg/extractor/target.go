@@ -0,0 +1,216 @@
+package extractor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultTargets are the functions of package xlate, as accepted by ParseTarget
+var DefaultTargets = []string{
+	"github.com/mpictor/go-xtract/pkg/xlate.T",
+	"github.com/mpictor/go-xtract/pkg/xlate.TC:1c,2",
+	"github.com/mpictor/go-xtract/pkg/xlate.TN:1,2",
+	"github.com/mpictor/go-xtract/pkg/xlate.TCN:1c,2,3",
+}
+
+// TargetList is a repeatable flag of the targets to extract, each as accepted by ParseTarget, which checks it. The
+// first value given replaces the default.
+type TargetList struct {
+	specs []string
+	set   bool
+}
+
+// NewTargetList creates a TargetList holding defaults, such as DefaultTargets, until a value is given
+func NewTargetList(defaults ...string) *TargetList { return &TargetList{specs: defaults} }
+
+func (l *TargetList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(l.specs, " ")
+}
+
+func (l *TargetList) Set(spec string) error {
+	if _, err := ParseTarget(spec); err != nil {
+		return err
+	}
+	if !l.set {
+		l.specs = nil
+		l.set = true
+	}
+	l.specs = append(l.specs, spec)
+	return nil
+}
+
+// Targets parses the targets held
+func (l *TargetList) Targets() ([]Target, error) {
+	targets := make([]Target, 0, len(l.specs))
+	for _, spec := range l.specs {
+		t, err := ParseTarget(spec)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// Target is a function or method whose calls are extracted, along with the positions of its arguments which hold
+// the message and, optionally, its context and plural form. Positions are 0-based; -1 means the argument is absent.
+type Target struct {
+	Package string // import path of the package declaring the function, or the method's receiver type
+	Name    string // function or method name
+	Recv    string // receiver type name for methods, including interface methods; prefixed by '*' if a pointer
+
+	Msg    int // message argument
+	Ctx    int // context argument, or -1
+	Plural int // plural form argument, or -1
+}
+
+// NewTarget creates a Target for the given function, whose first argument is the message
+func NewTarget(pkg, name string) Target {
+	return Target{Package: pkg, Name: name, Msg: 0, Ctx: -1, Plural: -1}
+}
+
+// NewMethodTarget creates a Target for the given method, whose first argument is the message. recv is the name of
+// the receiver type, optionally prefixed by '*'.
+func NewMethodTarget(pkg, recv, name string) Target {
+	t := NewTarget(pkg, name)
+	t.Recv = recv
+	return t
+}
+
+// IsMethod reports whether the target is a method rather than a package-level function
+func (t Target) IsMethod() bool { return t.Recv != "" }
+
+// ParseTarget parses a target specification of the form 'path/to/pkg.Func' or 'path/to/pkg.Func:ARGS'. Methods,
+// including interface methods, are given as '(path/to/pkg.Type).Method' or '(*path/to/pkg.Type).Method'; where the
+// type name is exported, 'path/to/pkg.Type.Method' may be used too. ARGS is a
+// comma-separated list of 1-based argument positions, following xgettext's --keyword option: the first plain
+// number is the message, a second plain number is the plural form (which must follow the message), and a number
+// suffixed with 'c' is the context. For example 'errs.New:2', 'xlate.TC:1c,2' or 'xlate.TN:1,2'. Without ARGS,
+// the first argument is the message.
+func ParseTarget(spec string) (Target, error) {
+	name, args := spec, ""
+	if colon := strings.LastIndex(spec, ":"); colon >= 0 {
+		name, args = spec[:colon], spec[colon+1:]
+	}
+
+	t, err := parseTargetName(name)
+	if err != nil {
+		return Target{}, errors.Wrapf(err, "'%s' is not a valid qualified function or method name; allowed values: "+
+			"'pkg.Func', 'path.to/some/pkg.Func' or '(*path.to/some/pkg.Type).Method', optionally followed by ':ARGS'",
+			spec)
+	}
+	if args == "" {
+		return t, nil
+	}
+
+	msg := false
+	for _, arg := range strings.Split(args, ",") {
+		isCtx := strings.HasSuffix(arg, "c")
+		n, err := strconv.Atoi(strings.TrimSuffix(arg, "c"))
+		if err != nil || n < 1 {
+			return Target{}, errors.Errorf("%s: invalid argument position '%s'", spec, arg)
+		}
+		n-- // 0-based
+		switch {
+		case isCtx && t.Ctx < 0:
+			t.Ctx = n
+		case !isCtx && !msg:
+			t.Msg = n
+			msg = true
+		case !isCtx && t.Plural < 0 && n > t.Msg:
+			t.Plural = n
+		default:
+			return Target{}, errors.Errorf("%s: too many argument positions", spec)
+		}
+	}
+	if t.Msg == t.Ctx || t.Msg == t.Plural || (t.Ctx >= 0 && t.Ctx == t.Plural) {
+		return Target{}, errors.Errorf("%s: argument positions must differ", spec)
+	}
+	return t, nil
+}
+
+// parseTargetName parses the part of a target specification preceding ARGS
+func parseTargetName(name string) (Target, error) {
+	if strings.HasPrefix(name, "(") {
+		end := strings.Index(name, ").")
+		if end < 0 || end == len(name)-2 {
+			return Target{}, errors.New("expected '(Type).Method'")
+		}
+		recv, method := name[1:end], name[end+2:]
+		star := ""
+		if strings.HasPrefix(recv, "*") {
+			star, recv = "*", recv[1:]
+		}
+		recvType, err := parseTargetName(recv)
+		if err != nil || recvType.IsMethod() {
+			return Target{}, errors.New("expected qualified receiver type")
+		}
+		return NewMethodTarget(recvType.Package, star+recvType.Name, method), nil
+	}
+
+	dot := strings.LastIndex(name, ".")
+	slash := strings.LastIndex(name, "/")
+	if dot < 0 || slash > dot || dot == len(name)-1 {
+		return Target{}, errors.New("expected 'pkg.Func'")
+	}
+	pkg, fn := name[:dot], name[dot+1:]
+
+	// 'pkg.Type.Method'. Only exported types are recognized, as package paths may themselves contain dots.
+	if recvDot := strings.LastIndex(pkg, "."); recvDot > slash && recvDot < len(pkg)-1 {
+		recv := pkg[recvDot+1:]
+		if unicode.IsUpper([]rune(recv)[0]) {
+			return NewMethodTarget(pkg[:recvDot], recv, fn), nil
+		}
+	}
+	return NewTarget(pkg, fn), nil
+}
+
+// String returns the target in the form accepted by ParseTarget
+func (t Target) String() string {
+	s := t.Package + "." + t.Name
+	if t.IsMethod() {
+		star, recv := "", t.Recv
+		if strings.HasPrefix(recv, "*") {
+			star, recv = "*", recv[1:]
+		}
+		s = "(" + star + t.Package + "." + recv + ")." + t.Name
+	}
+	if t.Msg == 0 && t.Ctx < 0 && t.Plural < 0 {
+		return s
+	}
+	// in order of position; the message always precedes the plural form
+	args := make([]string, t.maxArg()+1)
+	args[t.Msg] = strconv.Itoa(t.Msg + 1)
+	if t.Plural >= 0 {
+		args[t.Plural] = strconv.Itoa(t.Plural + 1)
+	}
+	if t.Ctx >= 0 {
+		args[t.Ctx] = fmt.Sprintf("%dc", t.Ctx+1)
+	}
+	var used []string
+	for _, a := range args {
+		if a != "" {
+			used = append(used, a)
+		}
+	}
+	return s + ":" + strings.Join(used, ",")
+}
+
+// maxArg returns the highest argument position used by the target
+func (t Target) maxArg() int {
+	max := t.Msg
+	if t.Ctx > max {
+		max = t.Ctx
+	}
+	if t.Plural > max {
+		max = t.Plural
+	}
+	return max
+}
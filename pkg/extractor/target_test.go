@@ -0,0 +1,75 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		want Target
+	}{
+		{"fmt.Println", Target{Package: "fmt", Name: "Println", Msg: 0, Ctx: -1, Plural: -1}},
+		{"path.to/some/pkg.Func", Target{Package: "path.to/some/pkg", Name: "Func", Msg: 0, Ctx: -1, Plural: -1}},
+		{"a/errs.New:2", Target{Package: "a/errs", Name: "New", Msg: 1, Ctx: -1, Plural: -1}},
+		{"a/xlate.TC:1c,2", Target{Package: "a/xlate", Name: "TC", Msg: 1, Ctx: 0, Plural: -1}},
+		{"a/xlate.TN:1,2", Target{Package: "a/xlate", Name: "TN", Msg: 0, Ctx: -1, Plural: 1}},
+		{"a/xlate.TNC:1c,2,3", Target{Package: "a/xlate", Name: "TNC", Msg: 1, Ctx: 0, Plural: 2}},
+	} {
+		got, err := ParseTarget(tc.spec)
+		require.NoError(t, err, tc.spec)
+		assert.Equal(t, tc.want, got, tc.spec)
+		assert.Equal(t, tc.spec, got.String(), "must round-trip")
+	}
+
+	for _, spec := range []string{"Func", "path/pkg", "pkg.", "pkg.Func:0", "pkg.Func:x", "pkg.Func:1,1", "pkg.Func:1,2,3", "pkg.Func:2,1"} {
+		_, err := ParseTarget(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestParseMethodTarget(t *testing.T) {
+	for _, tc := range []struct {
+		spec, str string
+		want      Target
+	}{
+		{"(*a/i18n.Localizer).T", "", Target{Package: "a/i18n", Recv: "*Localizer", Name: "T", Msg: 0, Ctx: -1, Plural: -1}},
+		{"(a/i18n.localizer).T:2", "", Target{Package: "a/i18n", Recv: "localizer", Name: "T", Msg: 1, Ctx: -1, Plural: -1}},
+		{"a/i18n.Translator.T", "(a/i18n.Translator).T", Target{Package: "a/i18n", Recv: "Translator", Name: "T", Msg: 0, Ctx: -1, Plural: -1}},
+		{"gopkg.in/yaml.v2.Marshal", "", Target{Package: "gopkg.in/yaml.v2", Name: "Marshal", Msg: 0, Ctx: -1, Plural: -1}},
+	} {
+		got, err := ParseTarget(tc.spec)
+		require.NoError(t, err, tc.spec)
+		assert.Equal(t, tc.want, got, tc.spec)
+		if tc.str == "" {
+			tc.str = tc.spec
+		}
+		assert.Equal(t, tc.str, got.String())
+	}
+
+	for _, spec := range []string{"(a/i18n.Localizer)", "(a/i18n.Localizer).", "(Localizer).T", "(*(a/b.C).D).T"} {
+		_, err := ParseTarget(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestTargetList(t *testing.T) {
+	l := NewTargetList(DefaultTargets...)
+	targets, err := l.Targets()
+	require.NoError(t, err)
+	assert.Len(t, targets, len(DefaultTargets))
+
+	assert.Error(t, l.Set("bogus"))
+	require.NoError(t, l.Set("a/errs.New:2"))
+	require.NoError(t, l.Set("a/xlate.TC:1c,2"))
+	assert.Equal(t, "a/errs.New:2 a/xlate.TC:1c,2", l.String(), "values replace the default")
+	targets, err = l.Targets()
+	require.NoError(t, err)
+	assert.Equal(t, []Target{
+		{Package: "a/errs", Name: "New", Msg: 1, Ctx: -1, Plural: -1},
+		{Package: "a/xlate", Name: "TC", Msg: 1, Ctx: 0, Plural: -1},
+	}, targets)
+}
@@ -0,0 +1,227 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// dependencies are type-checked from source too, which is slower than reading export data but does not require
+// them to have been compiled by a matching toolchain
+const typedLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes |
+	packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
+
+// NewTyped creates a new Extractor for the given function, using type information to find calls to it. Calls are
+// matched by object identity rather than by spelling, so dot-imports, calls from within the target's own package
+// and calls through variables holding the function (f := xlate.T; f(x)) are all found.
+//
+// The files passed to ProcessFiles must belong to packages which can be loaded by the go tool. Files excluded
+// from the build are processed without type information, as with New.
+func NewTyped(targetFuncPackage, targetFuncName string) Extractor {
+	return NewTypedTargets(NewTarget(targetFuncPackage, targetFuncName))
+}
+
+// NewTypedTargets creates a new Extractor for calls to any of the given targets, using type information as
+// described for NewTyped. Method targets are matched by receiver type, including calls through method values and
+// method expressions; interface method targets match calls through the interface.
+func NewTypedTargets(targets ...Target) Extractor {
+	t := &typedExtractor{extractor: newExtractor()}
+	t.targets = targets
+	return t
+}
+
+// typedExtractor implements the ast.Visitor interface, using type information from go/packages
+type typedExtractor struct {
+	*extractor
+
+	// finds calls to the targets
+	matcher *Matcher
+
+	// packages loaded by processFiles, and the one the current file belongs to
+	loaded []*packages.Package
+	info   *types.Info
+	byFile map[string]*packages.Package
+}
+
+// processFiles loads the packages containing the given files, then walks each file with type information
+func (r *typedExtractor) processFiles(files []string) error {
+	wanted := make(map[string]bool, len(files))
+	var dirs []string
+	for _, f := range files {
+		wanted[f] = true
+		dir := filepath.Dir(f)
+		if !contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: typedLoadMode,
+		Dir:  dirs[0],
+		Fset: fset,
+	}
+	util.Log.Printf("loading packages %v", dirs)
+	pkgs, err := packages.Load(cfg, dirs...)
+	if err != nil {
+		return errors.Wrap(err, "failed to load packages")
+	}
+	r.position = fset.Position
+	r.loaded = pkgs
+	r.byFile = make(map[string]*packages.Package)
+
+	r.matcher = NewMatcher(r.targets...)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			// type errors leave the type information incomplete, but usually still usable
+			util.Log.Printf("package %s: %s", pkg.PkgPath, e)
+		}
+		if pkg.Types != nil {
+			r.matcher.AddPackage(pkg.Types)
+		}
+	})
+	if !r.matcher.Found() {
+		util.Log.Printf("no target function is used by any loaded package")
+	}
+	r.findAliases()
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			filename := fset.File(file.Pos()).Name()
+			if !wanted[filename] {
+				continue
+			}
+			delete(wanted, filename)
+			r.byFile[filename] = pkg
+
+			util.Log.Printf("processing file %s", filename)
+			r.Load(file, filename)
+			ast.Walk(r, file)
+		}
+	}
+
+	// remaining files are not part of the build; fall back to syntax alone
+	r.position = util.Position
+	for _, filename := range files {
+		if !wanted[filename] {
+			continue
+		}
+		util.Log.Printf("file %s is not part of any loaded package; processing without type information", filename)
+		if err := processFile(r.extractor, filename); err != nil {
+			return err
+		}
+	}
+	return r.Err()
+}
+
+// findAliases records variables which are assigned a target function, so that calls through them are matched.
+// Repeats until no new aliases are found, to follow chains such as g := f.
+func (r *typedExtractor) findAliases() {
+	for {
+		found := false
+		for _, pkg := range r.loaded {
+			found = r.matcher.AddAliases(pkg.TypesInfo, pkg.Syntax) || found
+		}
+		if !found {
+			return
+		}
+	}
+}
+
+// Load sets the current file. Type information must already have been loaded by ProcessFiles.
+func (r *typedExtractor) Load(file *ast.File, filename string) {
+	r.extractor.Load(file, filename)
+	r.info = nil
+	if pkg, ok := r.byFile[filename]; ok {
+		r.info = pkg.TypesInfo
+		r.pkgPath = pkg.PkgPath
+	}
+}
+
+// Visit visit a node in the go file's AST
+func (r *typedExtractor) Visit(node ast.Node) ast.Visitor {
+	call, ok := node.(*ast.CallExpr)
+	if !ok || r.info == nil {
+		return r
+	}
+	target, args := r.matcher.Match(r.info, call)
+	if target == nil {
+		return r // wrong function
+	}
+	if !r.extractArgs(call, args, target, r.extractValue) {
+		return r // failed to extract
+	}
+
+	// stop traversing this branch of the tree
+	return nil
+}
+
+// extractValue returns the value of a constant string expression, or of a package-level string var which is
+// initialized with one
+func (r *typedExtractor) extractValue(expr ast.Expr) (string, bool) {
+	if tv, ok := r.info.Types[expr]; ok && tv.Value != nil {
+		if tv.Value.Kind() != constant.String {
+			return "", false
+		}
+		return constant.StringVal(tv.Value), true
+	}
+
+	var obj types.Object
+	switch e := expr.(type) {
+	case *ast.Ident:
+		obj = r.info.Uses[e]
+	case *ast.SelectorExpr:
+		obj = r.info.Uses[e.Sel]
+	}
+	v, ok := obj.(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		util.Log.Printf("%T is not a package-level var", expr)
+		return "", false
+	}
+
+	ref := v.Pkg().Name() + "." + v.Name()
+	value, err := r.resolveSymbol(filepath.Dir(r.currentFile), v.Pkg().Path(), v.Name())
+	if err != nil {
+		r.unresolve(expr, ref, err)
+		return "", false
+	}
+	util.Log.Printf("successfully resolved %s = %s", ref, value)
+	return value, true
+}
+
+// lookupTarget finds the object for a function or method target declared in pkg. For methods, the object is that
+// of the method as declared, which for promoted methods is on the embedded type, and for interfaces is the
+// interface method; calls through an interface are matched, but calls to concrete implementations are not.
+func lookupTarget(pkg *types.Package, t Target) types.Object {
+	if !t.IsMethod() {
+		return pkg.Scope().Lookup(t.Name)
+	}
+	tn, ok := pkg.Scope().Lookup(strings.TrimPrefix(t.Recv, "*")).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	obj, _, _ := types.LookupFieldOrMethod(tn.Type(), true, pkg, t.Name)
+	if _, ok := obj.(*types.Func); !ok {
+		return nil
+	}
+	return obj
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
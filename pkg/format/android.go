@@ -0,0 +1,102 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/extractor"
+	"github.com/mpictor/go-xtract/pkg/plural"
+)
+
+// writeAndroid writes an Android string resource file, res/values/strings.xml. Translator comments are written
+// as xml comments preceding each string. A KeyCollisionError is returned if distinct keys have the same resource
+// name.
+func writeAndroid(w io.Writer, m *Messages) error {
+	c, err := m.Catalog()
+	if err != nil {
+		return err
+	}
+	if err := androidCollisions(m); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<resources>\n")
+	for _, k := range m.Keys(OrderKey) {
+		if meta := c.Meta[k]; meta != nil && meta.Description != "" {
+			buf.WriteString("    <!-- " + xmlComment(meta.Description) + " -->\n")
+		}
+		name := androidName(k)
+		forms, ok := c.Plurals[k]
+		if !ok {
+			buf.WriteString(`    <string name="` + name + `">` + androidEscape(c.Messages[k]) + "</string>\n")
+			continue
+		}
+		buf.WriteString(`    <plurals name="` + name + "\">\n")
+		for _, cat := range plural.Categories {
+			if form, ok := forms[cat]; ok {
+				buf.WriteString(`        <item quantity="` + string(cat) + `">` + androidEscape(form) + "</item>\n")
+			}
+		}
+		buf.WriteString("    </plurals>\n")
+	}
+	buf.WriteString("</resources>\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// androidCollisions returns a KeyCollisionError if the keys of distinct messages have the same resource name, as
+// in "a/b" and "a_b", or the same field in R.string, as in "a.b" and "a_b"
+func androidCollisions(m *Messages) error {
+	keyOf := m.Options.keys()
+	byName := make(map[string]extractor.VarList)
+	for _, v := range m.Sorted(OrderKey) {
+		name := strings.ReplaceAll(androidName(keyOf(v)), ".", "_")
+		byName[name] = append(byName[name], v)
+	}
+	collisions := make(map[string]extractor.VarList)
+	for name, vars := range byName {
+		if len(vars) > 1 {
+			collisions[name] = vars
+		}
+	}
+	if len(collisions) > 0 {
+		return &KeyCollisionError{collisions}
+	}
+	return nil
+}
+
+// androidName replaces characters not allowed in resource names with '_'
+func androidName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '.' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// androidEscape escapes s for use as the text of an Android string resource: xml special characters, quotes and
+// backslashes, newlines and tabs, and a leading '@' or '?', which would otherwise make it a reference
+func androidEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	out := strings.NewReplacer(
+		`\`, `\\`,
+		"&#39;", `\'`,
+		"&#34;", `\"`,
+		"&#xA;", `\n`,
+		"&#x9;", `\t`,
+	).Replace(b.String())
+	if strings.HasPrefix(out, "@") || strings.HasPrefix(out, "?") {
+		out = `\` + out
+	}
+	return out
+}
+
+// xmlComment makes s safe to use in an xml comment, which may not contain "--"
+func xmlComment(s string) string {
+	return strings.ReplaceAll(s, "--", "- -")
+}
@@ -0,0 +1,83 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/plural"
+)
+
+// writeAppleStrings writes an Apple .strings file. Plural messages, which are not supported there, are omitted;
+// see writeAppleStringsdict.
+func writeAppleStrings(w io.Writer, m *Messages) error {
+	c, err := m.Catalog()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, k := range m.Keys(OrderKey) {
+		msg, ok := c.Messages[k]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		if meta := c.Meta[k]; meta != nil && meta.Description != "" {
+			buf.WriteString("/* " + strings.ReplaceAll(meta.Description, "*/", "* /") + " */\n")
+		}
+		fmt.Fprintf(&buf, "\"%s\" = \"%s\";\n", appleEscape(k), appleEscape(msg))
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func appleEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s)
+}
+
+// writeAppleStringsdict writes the plural messages as an Apple .stringsdict property list. Each message is
+// a format with the single variable 'count', an integer.
+func writeAppleStringsdict(w io.Writer, m *Messages) error {
+	c, err := m.Catalog()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	buf.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	entry := func(indent, key, value string) {
+		buf.WriteString(indent + "<key>" + xmlText(key) + "</key>\n")
+		buf.WriteString(indent + "<string>" + xmlText(value) + "</string>\n")
+	}
+	for _, k := range m.Keys(OrderKey) {
+		forms, ok := c.Plurals[k]
+		if !ok {
+			continue
+		}
+		buf.WriteString("  <key>" + xmlText(k) + "</key>\n  <dict>\n")
+		entry("    ", "NSStringLocalizedFormatKey", "%#@count@")
+		buf.WriteString("    <key>count</key>\n    <dict>\n")
+		entry("      ", "NSStringFormatSpecTypeKey", "NSStringPluralRuleType")
+		entry("      ", "NSStringFormatValueTypeKey", "d")
+		for _, cat := range plural.Categories {
+			if form, ok := forms[cat]; ok {
+				entry("      ", string(cat), form)
+			}
+		}
+		buf.WriteString("    </dict>\n  </dict>\n")
+	}
+	buf.WriteString("</dict>\n</plist>\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func xmlText(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
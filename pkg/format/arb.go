@@ -0,0 +1,102 @@
+package format
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/plural"
+)
+
+// writeARB writes a Flutter application resource bundle. Plural messages are written in ICU syntax, with the
+// count as the placeholder 'count'.
+func writeARB(w io.Writer, m *Messages) error {
+	c, err := m.Catalog()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("{\n  \"@@locale\": ")
+	if err := catalog.Encode(&buf, m.Options.SourceLang, "  "); err != nil {
+		return err
+	}
+	type placeholder struct {
+		Type string `json:"type"`
+	}
+	type arbMeta struct {
+		Description  string                 `json:"description,omitempty"`
+		Context      string                 `json:"context,omitempty"`
+		References   []string               `json:"x-references,omitempty"`
+		Placeholders map[string]placeholder `json:"placeholders,omitempty"`
+	}
+	for _, k := range m.Keys(OrderKey) {
+		var meta arbMeta
+		if cm := c.Meta[k]; cm != nil {
+			meta = arbMeta{Description: cm.Description, Context: cm.Context, References: cm.References}
+		}
+		msg := icuEscape(c.Messages[k], false)
+		if forms, ok := c.Plurals[k]; ok {
+			msg = icuPlural("count", forms)
+			meta.Placeholders = map[string]placeholder{"count": {"int"}}
+		}
+		buf.WriteString(",\n  ")
+		if err := catalog.Encode(&buf, k, "  "); err != nil {
+			return err
+		}
+		buf.WriteString(": ")
+		if err := catalog.Encode(&buf, msg, "  "); err != nil {
+			return err
+		}
+		if meta.Description == "" && meta.Context == "" && meta.References == nil && meta.Placeholders == nil {
+			continue
+		}
+		buf.WriteString(",\n  ")
+		if err := catalog.Encode(&buf, catalog.MetaPrefix+k, "  "); err != nil {
+			return err
+		}
+		buf.WriteString(": ")
+		if err := catalog.Encode(&buf, meta, "  "); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("\n}\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// icuPlural formats the forms of a plural message as an ICU plural argument
+func icuPlural(arg string, forms catalog.Plural) string {
+	var sb strings.Builder
+	sb.WriteString("{" + arg + ", plural,")
+	for _, cat := range plural.Categories {
+		if form, ok := forms[cat]; ok {
+			sb.WriteString(" " + string(cat) + "{" + icuEscape(form, true) + "}")
+		}
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// icuEscape quotes runs of the ICU syntax characters in s: braces, which would otherwise begin arguments, and
+// within plural forms '#', which would be replaced with the count. Apostrophes are doubled.
+func icuEscape(s string, inPlural bool) string {
+	special := func(r rune) bool { return r == '{' || r == '}' || inPlural && r == '#' }
+	var sb strings.Builder
+	quoted := false
+	for _, r := range s {
+		if special(r) != quoted {
+			sb.WriteByte('\'')
+			quoted = !quoted
+		}
+		if r == '\'' {
+			sb.WriteString("''")
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	if quoted {
+		sb.WriteByte('\'')
+	}
+	return sb.String()
+}
@@ -0,0 +1,34 @@
+package format
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/plural"
+)
+
+// writeCSV writes one row per message, for review in a spreadsheet. The columns are the key, context, text,
+// plural text, translator comments and references; the singular of a plural message is its text.
+func writeCSV(w io.Writer, m *Messages) error {
+	c, err := m.Catalog()
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"key", "context", "text", "plural", "description", "references"})
+	for _, k := range m.Keys(OrderKey) {
+		text, pl := c.Messages[k], ""
+		if forms, ok := c.Plurals[k]; ok {
+			text, pl = forms[plural.One], forms[plural.Other]
+		}
+		var meta catalog.Meta
+		if cm := c.Meta[k]; cm != nil {
+			meta = *cm
+		}
+		cw.Write([]string{k, meta.Context, text, pl, meta.Description, strings.Join(meta.References, " ")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
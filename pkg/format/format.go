@@ -0,0 +1,159 @@
+// Package format defines the output formats of xtract, and a registry from which
+// they are selected by name with xtract's -format flag.
+//
+// A Writer is given the extracted messages and writes them in its format. The
+// formats built in are registered by this package; others are added by
+// registering them from an init func, and building xtract with the package
+// holding them imported:
+//
+//	package main
+//
+//	import (
+//		"github.com/mpictor/go-xtract/pkg/cli"
+//		_ "example.com/corp/xtractformats"
+//	)
+//
+//	func main() { cli.Main() }
+package format
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/extractor"
+)
+
+// Writer writes extracted messages in an output format
+type Writer interface {
+	Write(w io.Writer, m *Messages) error
+}
+
+// WriterFunc adapts a func to a Writer
+type WriterFunc func(w io.Writer, m *Messages) error
+
+// Write calls f(w, m)
+func (f WriterFunc) Write(w io.Writer, m *Messages) error { return f(w, m) }
+
+// Messages are the extracted messages to be written, and the options for writing them
+type Messages struct {
+	Vars extractor.VarList
+	// the files the messages were extracted from, in the order given, for OrderFile
+	Files   []string
+	Options Options
+
+	catalog *catalog.Catalog
+}
+
+// Options are settings which formats use if applicable
+type Options struct {
+	// language of the extracted messages, such as "en"
+	SourceLang string
+	// source of the template used by the template format
+	Template string
+	// include source references, where the format has a place for them
+	Refs bool
+	// include translator comments, where the format has a place for them
+	Comments bool
+	// order of the messages; formats have their own default
+	Order Order
+	// derives the keys of messages in catalog formats; VarKey if nil
+	Keys KeyStrategy
+}
+
+// Sorted returns the messages in the order given by Options.Order, or def if that is not set
+func (m *Messages) Sorted(def Order) extractor.VarList {
+	order := m.Options.Order
+	if order == "" {
+		order = def
+	}
+	vars := append(extractor.VarList(nil), m.Vars...)
+	Sort(vars, order, m.Files, m.Options.Keys)
+	return vars
+}
+
+// Keys returns the keys of the messages in the catalog, in the order of Sorted(def)
+func (m *Messages) Keys(def Order) []string {
+	var keys []string
+	seen := make(map[string]bool, len(m.Vars))
+	for _, v := range m.Sorted(def) {
+		if k := m.Options.keys()(v); !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Strings returns the text of the messages, in the order of Sorted(def) and without duplicates
+func (m *Messages) Strings(def Order) []string {
+	var strs []string
+	seen := make(map[string]bool, len(m.Vars))
+	for _, v := range m.Sorted(def) {
+		if !seen[v.Val] {
+			seen[v.Val] = true
+			strs = append(strs, v.Val)
+		}
+	}
+	return strs
+}
+
+// Catalog returns the messages keyed as in the json assets; see BuildCatalog
+func (m *Messages) Catalog() (*catalog.Catalog, error) {
+	if m.catalog == nil {
+		c, err := BuildCatalog(m.Vars, m.Options)
+		if err != nil {
+			return nil, err
+		}
+		m.catalog = c
+	}
+	return m.catalog, nil
+}
+
+var (
+	mu      sync.RWMutex
+	writers = make(map[string]Writer)
+)
+
+// Register makes a format available by name. It panics if the name is already registered.
+func Register(name string, w Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := writers[name]; dup {
+		panic(fmt.Sprintf("format: %s registered twice", name))
+	}
+	writers[name] = w
+}
+
+// Lookup returns the format registered with name
+func Lookup(name string) (Writer, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	w, ok := writers[name]
+	return w, ok
+}
+
+// Names returns the names of the registered formats, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("json", WriterFunc(writeJSON))
+	Register("template", WriterFunc(writeTemplate))
+	Register("pot", WriterFunc(writePOT))
+	Register("arb", WriterFunc(writeARB))
+	Register("android", WriterFunc(writeAndroid))
+	Register("strings", WriterFunc(writeAppleStrings))
+	Register("stringsdict", WriterFunc(writeAppleStringsdict))
+	Register("csv", WriterFunc(writeCSV))
+}
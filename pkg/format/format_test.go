@@ -0,0 +1,168 @@
+package format
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mpictor/go-xtract/pkg/extractor"
+)
+
+var vars = extractor.VarList{
+	{Val: "Hello, World!", Vars: []string{"Greeting"}, Comments: []string{"shown on startup"}},
+	{Val: "Open", Ctx: "verb"},
+	{Val: "%d file deleted", Plural: "%d files deleted", Vars: []string{"Deleted"}},
+}
+
+func TestRegistry(t *testing.T) {
+	for _, name := range []string{"json", "template", "pot", "arb", "android", "strings", "stringsdict", "csv"} {
+		_, ok := Lookup(name)
+		assert.True(t, ok, name)
+	}
+	_, ok := Lookup("nope")
+	assert.False(t, ok)
+
+	Register("test-first", WriterFunc(func(w io.Writer, m *Messages) error {
+		_, err := io.WriteString(w, m.Strings(OrderKey)[0])
+		return err
+	}))
+	assert.Contains(t, Names(), "test-first")
+	assert.Panics(t, func() { Register("test-first", WriterFunc(writeJSON)) })
+
+	w, ok := Lookup("test-first")
+	require.True(t, ok)
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf, &Messages{Vars: vars}))
+	assert.Equal(t, "%d file deleted", buf.String())
+}
+
+func TestTemplate(t *testing.T) {
+	w, _ := Lookup("template")
+	var buf bytes.Buffer
+	m := &Messages{Vars: vars, Options: Options{Template: "{{range .Vars}}{{.Val}}|{{end}}"}}
+	require.NoError(t, w.Write(&buf, m))
+	assert.Equal(t, "%d file deleted|Hello, World!|Open|", buf.String())
+
+	m.Options.Template = "{{.Nope"
+	assert.Error(t, w.Write(&buf, m))
+}
+
+func TestCatalog(t *testing.T) {
+	c, err := (&Messages{Vars: vars, Options: Options{Comments: true}}).Catalog()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deleted", "Greeting", "verb_Open"}, c.Keys())
+	assert.Equal(t, "%d files deleted", c.Plurals["Deleted"]["other"])
+	assert.Equal(t, "shown on startup", c.Meta["Greeting"].Description)
+	assert.Equal(t, "verb", c.Meta["verb_Open"].Context)
+}
+
+func TestKeys(t *testing.T) {
+	v := extractor.ValVars{
+		Val:   "Access denied",
+		Vars:  []string{"ErrDenied"},
+		Decls: []extractor.VarDecl{{Name: "ErrDenied", PkgName: "auth", PkgPath: "example.com/app/internal/auth"}},
+	}
+	assert.Equal(t, "ErrDenied", VarKey(v))
+	assert.Equal(t, "auth.ErrDenied", PkgVarKey(v))
+	assert.Equal(t, "example.com/app/internal/auth.ErrDenied", PathVarKey(nil)(v))
+	for want, aliases := range map[string]map[string]string{
+		"app/internal/auth.ErrDenied":             {"example.com/app": "app", "example.com": "x"},
+		"a.ErrDenied":                             {"example.com/app/internal/auth": "a"},
+		"internal/auth.ErrDenied":                 {"example.com/app": ""},
+		"ErrDenied":                               {"example.com/app/internal/auth": ""},
+		"example.com/app/internal/auth.ErrDenied": {"example.com/ap": "no"},
+	} {
+		assert.Equal(t, want, PathVarKey(aliases)(v), aliases)
+	}
+	assert.Equal(t, "Access_denied", TextKey(v))
+	assert.Equal(t, "Access_denied", IDKey(extractor.ValVars{Val: "Access denied"}))
+	v.ID = "errors.denied"
+	assert.Equal(t, "errors.denied", IDKey(v))
+	assert.Regexp(t, "^h[0-9a-f]{16}$", HashKey(v))
+	assert.NotEqual(t, HashKey(v), HashKey(extractor.ValVars{Val: v.Val, Ctx: "x"}))
+
+	_, err := LookupKeyStrategy("pkgvar")
+	assert.NoError(t, err)
+	_, err = LookupKeyStrategy("nope")
+	assert.Error(t, err)
+}
+
+func TestKeyCollision(t *testing.T) {
+	vars := extractor.VarList{
+		{Val: "Access denied", Vars: []string{"ErrDenied"}, Decls: []extractor.VarDecl{{Name: "ErrDenied", PkgName: "auth"}}},
+		{Val: "Payment denied", Vars: []string{"ErrDenied"}, Decls: []extractor.VarDecl{{Name: "ErrDenied", PkgName: "billing"}}},
+		{Val: "a.b"},
+		{Val: "a b"},
+	}
+	_, err := BuildCatalog(vars, Options{})
+	require.Error(t, err)
+	collision, ok := err.(*KeyCollisionError)
+	require.True(t, ok)
+	assert.Len(t, collision.Keys, 2)
+	assert.Len(t, collision.Keys["ErrDenied"], 2)
+	assert.Len(t, collision.Keys["a_b"], 2)
+
+	_, err = BuildCatalog(vars, Options{Keys: HashKey})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteKeyReport(&buf, &Messages{Vars: vars[:2], Options: Options{Keys: PkgVarKey}}))
+	assert.Equal(t, "key\tcontext\ttext\tvars\n"+
+		"auth.ErrDenied\t\tAccess denied\tauth.ErrDenied\n"+
+		"billing.ErrDenied\t\tPayment denied\tbilling.ErrDenied\n", buf.String())
+}
+
+func TestAndroidCollision(t *testing.T) {
+	vars := extractor.VarList{
+		{Val: "Access denied", ID: "auth/errors.Denied"},
+		{Val: "Payment denied", ID: "auth_errors.Denied"},
+		{Val: "Open", ID: "menu.open"},
+		{Val: "Open file", ID: "menu_open"},
+		{Val: "Close", ID: "menu.close"},
+	}
+	err := writeAndroid(io.Discard, &Messages{Vars: vars, Options: Options{Keys: IDKey}})
+	require.Error(t, err)
+	collision, ok := err.(*KeyCollisionError)
+	require.True(t, ok)
+	assert.Len(t, collision.Keys, 2)
+	assert.Len(t, collision.Keys["auth_errors_Denied"], 2)
+	assert.Len(t, collision.Keys["menu_open"], 2)
+
+	assert.NoError(t, writeAndroid(io.Discard, &Messages{Vars: vars[2:3], Options: Options{Keys: IDKey}}))
+}
+
+func TestSort(t *testing.T) {
+	vars := extractor.VarList{
+		{Val: "b", Refs: []extractor.Ref{{File: "b.go", Line: 3}, {File: "a.go", Line: 9}}},
+		{Val: "a", Refs: []extractor.Ref{{File: "b.go", Line: 1}}},
+		{Val: "c", Vars: []string{"A"}},
+		{Val: "d", Refs: []extractor.Ref{{File: "a.go", Line: 9, Column: 2}}},
+	}
+	vals := func(vars extractor.VarList) (vals []string) {
+		for _, v := range vars {
+			vals = append(vals, v.Val)
+		}
+		return vals
+	}
+	m := &Messages{Vars: vars, Files: []string{"b.go", "a.go"}}
+	assert.Equal(t, []string{"c", "a", "b", "d"}, vals(m.Sorted(OrderKey)))
+	assert.Equal(t, []string{"b", "d", "a", "c"}, vals(m.Sorted(OrderPosition)))
+	assert.Equal(t, []string{"a", "b", "d", "c"}, vals(m.Sorted(OrderFile)))
+	m.Options.Order = OrderPosition
+	assert.Equal(t, []string{"b", "d", "a", "c"}, vals(m.Sorted(OrderKey)))
+	assert.Equal(t, "b", vars[0].Val, "Sorted must not modify Vars")
+
+	_, err := ParseOrder("random")
+	assert.Error(t, err)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, "It''s '{{'.Name'}}'", icuEscape("It's {{.Name}}", false))
+	assert.Equal(t, "'#'%d", icuEscape("#%d", true))
+	assert.Equal(t, "#%d", icuEscape("#%d", false))
+	assert.Equal(t, `\@me \'a\' &amp; \"b\"\n`, androidEscape("@me 'a' & \"b\"\n"))
+	assert.Equal(t, `say \"hi\"\n`, appleEscape("say \"hi\"\n"))
+}
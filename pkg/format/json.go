@@ -0,0 +1,62 @@
+package format
+
+import (
+	"io"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/extractor"
+	"github.com/mpictor/go-xtract/pkg/plural"
+	"github.com/mpictor/go-xtract/pkg/util"
+)
+
+// writeJSON writes the json asset consumed by xlate
+func writeJSON(w io.Writer, m *Messages) error {
+	c, err := m.Catalog()
+	if err != nil {
+		return err
+	}
+	return c.WriteKeys(w, m.Keys(OrderKey))
+}
+
+// BuildCatalog creates a catalog of the extracted strings, keyed by Options.Keys. References and comments are
+// included in the metadata if requested. A KeyCollisionError is returned if distinct messages have the same key.
+func BuildCatalog(vars extractor.VarList, opts Options) (*catalog.Catalog, error) {
+	keyOf := opts.keys()
+	byKey := make(map[string]extractor.VarList)
+	c := catalog.New()
+	m := c.Messages
+	for _, v := range vars {
+		k := keyOf(v)
+		util.Log.Printf("val %q: vars %v - using %s as key", v.Val, v.Vars, k)
+		if byKey[k] = append(byKey[k], v); len(byKey[k]) > 1 {
+			continue
+		}
+		if v.Plural != "" {
+			// the primary language's singular and plural; translations add the forms their language needs
+			c.Plurals[k] = catalog.Plural{plural.One: v.Val, plural.Other: v.Plural}
+		} else {
+			m[k] = v.Val
+		}
+		meta := &catalog.Meta{Context: v.Ctx}
+		if opts.Refs {
+			for _, r := range v.Refs {
+				meta.References = append(meta.References, r.String())
+			}
+		}
+		if opts.Comments {
+			meta.Description = strings.Join(v.Comments, "\n")
+		}
+		c.Meta[k] = meta
+	}
+	collisions := make(map[string]extractor.VarList)
+	for k, vars := range byKey {
+		if len(vars) > 1 {
+			collisions[k] = vars
+		}
+	}
+	if len(collisions) > 0 {
+		return nil, &KeyCollisionError{collisions}
+	}
+	return c, nil
+}
@@ -0,0 +1,197 @@
+package format
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mpictor/go-xtract/pkg/extractor"
+)
+
+// KeyStrategy derives the key of a message in the json assets and other catalog formats
+type KeyStrategy func(v extractor.ValVars) string
+
+var keyStrategies = map[string]KeyStrategy{
+	"var":     VarKey,
+	"pkgvar":  PkgVarKey,
+	"pathvar": PathVarKey(nil),
+	"text":    TextKey,
+	"hash":    HashKey,
+	"id":      IDKey,
+}
+
+// LookupKeyStrategy returns the KeyStrategy with the given name: var, pkgvar, pathvar, text, hash or id
+func LookupKeyStrategy(name string) (KeyStrategy, error) {
+	if ks, ok := keyStrategies[name]; ok {
+		return ks, nil
+	}
+	var names []string
+	for n := range keyStrategies {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown key strategy %q; must be one of %s", name, strings.Join(names, ", "))
+}
+
+// VarKey is the name of the const/var holding the message, or its TextKey if it is held by none or several
+func VarKey(v extractor.ValVars) string {
+	if len(v.Vars) == 1 {
+		return v.Vars[0]
+	}
+	return TextKey(v)
+}
+
+// PkgVarKey is the name of the const/var holding the message qualified by the name of its package, as pkg.Name,
+// or its TextKey if it is held by none or several
+func PkgVarKey(v extractor.ValVars) string {
+	if len(v.Decls) != 1 {
+		return TextKey(v)
+	}
+	if d := v.Decls[0]; d.PkgName != "" {
+		return d.PkgName + "." + d.Name
+	}
+	return v.Decls[0].Name
+}
+
+// PathVarKey returns a KeyStrategy qualifying the name of the const/var holding the message by the import path of
+// its package, as path/to/pkg.Name, or else giving its TextKey. aliases maps import paths to shorter names used in
+// their place; an alias for a path also applies to the packages below it, and the longest matching path is used.
+// An empty alias leaves the name unqualified.
+func PathVarKey(aliases map[string]string) KeyStrategy {
+	return func(v extractor.ValVars) string {
+		if len(v.Decls) != 1 {
+			return TextKey(v)
+		}
+		d := v.Decls[0]
+		if qual := alias(d.PkgPath, aliases); qual != "" {
+			return qual + "." + d.Name
+		}
+		return d.Name
+	}
+}
+
+// alias replaces the longest prefix of path which has an alias with the alias
+func alias(path string, aliases map[string]string) string {
+	best := ""
+	for p := range aliases {
+		if (path == p || strings.HasPrefix(path, p+"/")) && len(p) > len(best) {
+			best = p
+		}
+	}
+	if best == "" {
+		return path
+	}
+	rest := strings.TrimPrefix(path[len(best):], "/")
+	switch {
+	case aliases[best] == "":
+		return rest
+	case rest == "":
+		return aliases[best]
+	}
+	return aliases[best] + "/" + rest
+}
+
+// TextKey is a sanitized copy of the message's context and text: each character but ASCII letters is replaced with
+// an underscore. Keys longer than 40 characters are truncated, ending with part of a hash of the text.
+func TextKey(v extractor.ValVars) string {
+	text := v.Val
+	if v.Ctx != "" {
+		text = v.Ctx + "_" + v.Val
+	}
+	sanitize := func(r rune) rune {
+		//replace all but letters with underscores
+		switch {
+		case r < 65, r > 122, r > 90 && r < 97:
+			return '_'
+		default:
+			return r
+		}
+	}
+	k := strings.Map(sanitize, text)
+	if len(k) > 40 {
+		sha := sha1.Sum([]byte(text))
+		enc := base64.RawStdEncoding.EncodeToString(sha[:])
+		if len(enc) > 10 {
+			enc = enc[:10]
+		}
+		k = k[:40-len(enc)] + string(enc)
+	}
+	return k
+}
+
+// HashKey is derived from a hash of the message's context and text, so that it changes only with them
+func HashKey(v extractor.ValVars) string {
+	sha := sha1.Sum([]byte(v.Ctx + "\x04" + v.Val))
+	return "h" + hex.EncodeToString(sha[:8])
+}
+
+// IDKey is the key given by an xtract:id directive (see extractor.IDDirective), or else the VarKey
+func IDKey(v extractor.ValVars) string {
+	if v.ID != "" {
+		return v.ID
+	}
+	return VarKey(v)
+}
+
+func (o Options) keys() KeyStrategy {
+	if o.Keys == nil {
+		return VarKey
+	}
+	return o.Keys
+}
+
+// KeyCollisionError reports keys which would be shared by distinct messages
+type KeyCollisionError struct {
+	// maps each key to the messages it would be shared by
+	Keys map[string]extractor.VarList
+}
+
+func (e *KeyCollisionError) Error() string {
+	keys := make([]string, 0, len(e.Keys))
+	for k := range e.Keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d key(s) shared by distinct messages:", len(keys))
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "\n  %s:", k)
+		for _, v := range e.Keys[k] {
+			fmt.Fprintf(&sb, " %q", v.Val)
+			if v.Ctx != "" {
+				fmt.Fprintf(&sb, " (context %q)", v.Ctx)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// WriteKeyReport writes the key of every message, in OrderKey unless Options.Order is set, as tab-separated
+// columns: the key, the message's context and text, and the consts/vars holding it, qualified by package
+func WriteKeyReport(w io.Writer, m *Messages) error {
+	keyOf := m.Options.keys()
+	cw := csv.NewWriter(w)
+	cw.Comma = '\t'
+	cw.Write([]string{"key", "context", "text", "vars"})
+	for _, v := range m.Sorted(OrderKey) {
+		var vars []string
+		for _, d := range v.Decls {
+			switch {
+			case d.PkgPath != "":
+				vars = append(vars, d.PkgPath+"."+d.Name)
+			case d.PkgName != "":
+				vars = append(vars, d.PkgName+"."+d.Name)
+			default:
+				vars = append(vars, d.Name)
+			}
+		}
+		cw.Write([]string{keyOf(v), v.Ctx, v.Val, strings.Join(vars, " ")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,93 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mpictor/go-xtract/pkg/extractor"
+)
+
+// Order is the order in which messages are written
+type Order string
+
+// Orders of messages. Ties are broken by text and then context.
+const (
+	// by key, as in the json assets
+	OrderKey Order = "key"
+	// by first use, in order of file name, line and column
+	OrderPosition Order = "position"
+	// by first use, in the order the files were given, then line and column
+	OrderFile Order = "file"
+)
+
+// ParseOrder returns the Order named s
+func ParseOrder(s string) (Order, error) {
+	switch o := Order(s); o {
+	case OrderKey, OrderPosition, OrderFile:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown order %q; must be %s, %s or %s", s, OrderKey, OrderPosition, OrderFile)
+}
+
+// Sort sorts vars in the given order. files are the files the messages were extracted from, in the order given,
+// for OrderFile, and keys derives keys for OrderKey; VarKey if nil. Messages which were not used anywhere come
+// last in OrderPosition and OrderFile.
+func Sort(vars extractor.VarList, order Order, files []string, keys KeyStrategy) {
+	if keys == nil {
+		keys = VarKey
+	}
+	index := make(map[string]int, len(files))
+	for i, f := range files {
+		index[f] = i
+	}
+	fileIndex := func(f string) int {
+		if i, ok := index[f]; ok {
+			return i
+		}
+		return len(files)
+	}
+	// before reports whether reference a precedes b
+	before := func(a, b extractor.Ref) bool {
+		if a.File != b.File {
+			if ia, ib := fileIndex(a.File), fileIndex(b.File); order == OrderFile && ia != ib {
+				return ia < ib
+			}
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	}
+	first := func(refs []extractor.Ref) (first extractor.Ref, ok bool) {
+		for i, r := range refs {
+			if i == 0 || before(r, first) {
+				first = r
+			}
+		}
+		return first, len(refs) > 0
+	}
+
+	sort.SliceStable(vars, func(i, j int) bool {
+		a, b := vars[i], vars[j]
+		switch order {
+		case OrderKey:
+			if ka, kb := keys(a), keys(b); ka != kb {
+				return ka < kb
+			}
+		case OrderPosition, OrderFile:
+			ra, oka := first(a.Refs)
+			rb, okb := first(b.Refs)
+			if oka != okb {
+				return oka
+			}
+			if oka && ra != rb {
+				return before(ra, rb)
+			}
+		}
+		if a.Val != b.Val {
+			return a.Val < b.Val
+		}
+		return a.Ctx < b.Ctx
+	})
+}
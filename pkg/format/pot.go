@@ -0,0 +1,31 @@
+package format
+
+import (
+	"io"
+
+	"github.com/mpictor/go-xtract/pkg/extractor"
+	"github.com/mpictor/go-xtract/pkg/po"
+)
+
+// writePOT writes a gettext template, with references and translator comments, by default in OrderPosition
+func writePOT(w io.Writer, m *Messages) error {
+	return POTFile(m.Sorted(OrderPosition)).Write(w)
+}
+
+// POTFile creates a gettext template holding the messages, in the order given
+func POTFile(vars extractor.VarList) *po.File {
+	pot := po.NewTemplate()
+	for _, v := range vars {
+		m := &po.Message{
+			ExtractedComments: v.Comments,
+			Ctx:               v.Ctx,
+			ID:                v.Val,
+			IDPlural:          v.Plural,
+		}
+		for _, r := range v.Refs {
+			m.References = append(m.References, r.String())
+		}
+		pot.Messages = append(pot.Messages, m)
+	}
+	return pot
+}
@@ -0,0 +1,38 @@
+package format
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/mpictor/go-xtract/pkg/extractor"
+	"github.com/mpictor/go-xtract/pkg/util"
+)
+
+// DefaultTemplate lists the text of each message on its own line
+// TODO(cmkirkla): fix character escaping in default template
+const DefaultTemplate = "{{range .Strings}}{{print .}}\n{{end}}"
+
+// writeTemplate executes Options.Template, or DefaultTemplate if empty. The template is given the text of the
+// messages as .Strings, and the messages as .Vars, by default in OrderKey.
+func writeTemplate(w io.Writer, m *Messages) error {
+	src := m.Options.Template
+	if src == "" {
+		src = DefaultTemplate
+	}
+	t, err := template.New("output").Parse(src)
+	if err != nil {
+		return fmt.Errorf("failed to parse output template: %w", err)
+	}
+	util.Log.Println("writing extracted strings")
+	if err := t.Execute(w, struct {
+		Strings []string
+		Vars    extractor.VarList
+	}{
+		Strings: m.Strings(OrderKey),
+		Vars:    m.Sorted(OrderKey),
+	}); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Package plural selects plural forms using the CLDR plural rules
+// (https://cldr.unicode.org/index/cldr-spec/plural-rules).
+//
+// Each language divides counts into some of the categories zero, one, two,
+// few, many and other; English uses one ("1 file") and other ("2 files"),
+// while Polish also uses few ("2 pliki") and many ("5 plików"). A translation
+// of a plural message holds one phrase per category its language uses.
+//
+// Only the rules for integer counts are implemented.
+package plural
+
+import "strings"
+
+// Category is a CLDR plural category
+type Category string
+
+// The CLDR plural categories. Other is used by every language.
+const (
+	Zero  Category = "zero"
+	One   Category = "one"
+	Two   Category = "two"
+	Few   Category = "few"
+	Many  Category = "many"
+	Other Category = "other"
+)
+
+// Categories lists all plural categories, in CLDR order
+var Categories = []Category{Zero, One, Two, Few, Many, Other}
+
+// Valid reports whether c is one of the CLDR plural categories
+func (c Category) Valid() bool {
+	for _, cat := range Categories {
+		if c == cat {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule selects the plural category for a count in some language
+type Rule struct {
+	// categories used by the language, in CLDR order
+	Categories []Category
+	form       func(n uint64) Category
+}
+
+// Form returns the category of the count n
+func (r *Rule) Form(n int) Category {
+	if n < 0 {
+		n = -n
+	}
+	return r.form(uint64(n))
+}
+
+// Default is the rule used for languages without a known rule: one for 1, other
+// for everything else, as in English and gettext's default Plural-Forms.
+var Default = oneIfOne
+
+// ForLocale returns the rule for a locale such as "pt-BR", "pt_PT" or "de".
+// Locales are matched case-insensitively, first in full and then by language
+// alone. ok is false if no rule is known, in which case Default is returned.
+func ForLocale(locale string) (r *Rule, ok bool) {
+	l := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if r, ok := rules[l]; ok {
+		return r, true
+	}
+	if i := strings.IndexByte(l, '-'); i >= 0 {
+		if r, ok := rules[l[:i]]; ok {
+			return r, true
+		}
+	}
+	return Default, false
+}
+
+// Form returns the category of the count n in the given locale
+func Form(locale string, n int) Category {
+	r, _ := ForLocale(locale)
+	return r.Form(n)
+}
+
+// Forms returns the categories used by the given locale, in CLDR order
+func Forms(locale string) []Category {
+	r, _ := ForLocale(locale)
+	return r.Categories
+}
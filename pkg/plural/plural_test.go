@@ -0,0 +1,50 @@
+package plural
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForm(t *testing.T) {
+	for _, tc := range []struct {
+		locale string
+		forms  map[int]Category
+	}{
+		{"en-US", map[int]Category{0: Other, 1: One, 2: Other, 11: Other, 21: Other}},
+		{"fr", map[int]Category{0: One, 1: One, 2: Other, 1000000: Many}},
+		{"pt_BR", map[int]Category{0: One, 1: One, 2: Other}},
+		{"pt-PT", map[int]Category{0: Other, 1: One, 2: Other}},
+		{"ja", map[int]Category{0: Other, 1: Other, 2: Other}},
+		{"ru", map[int]Category{1: One, 2: Few, 5: Many, 11: Many, 12: Many, 21: One, 22: Few, 111: Many}},
+		{"pl", map[int]Category{1: One, 2: Few, 5: Many, 21: Many, 22: Few, 112: Many}},
+		{"cs", map[int]Category{1: One, 3: Few, 5: Other}},
+		{"ar", map[int]Category{0: Zero, 1: One, 2: Two, 3: Few, 11: Many, 100: Other, 103: Few}},
+		{"cy", map[int]Category{0: Zero, 1: One, 2: Two, 3: Few, 6: Many, 4: Other}},
+		{"lv", map[int]Category{0: Zero, 1: One, 11: Zero, 21: One, 2: Other}},
+		{"unknown", map[int]Category{1: One, 2: Other}},
+	} {
+		for n, want := range tc.forms {
+			assert.Equal(t, want, Form(tc.locale, n), "%s: %d", tc.locale, n)
+		}
+	}
+	assert.Equal(t, One, Form("en", -1), "sign is ignored")
+}
+
+func TestForms(t *testing.T) {
+	assert.Equal(t, []Category{One, Other}, Forms("de"))
+	assert.Equal(t, []Category{Other}, Forms("zh-Hant"))
+	assert.Equal(t, Categories, Forms("ar"))
+
+	_, ok := ForLocale("xx")
+	assert.False(t, ok)
+	for l, r := range rules {
+		assert.Contains(t, r.Categories, Other, l)
+		for _, c := range r.Categories {
+			assert.True(t, c.Valid(), l)
+		}
+		for n := 0; n < 200; n++ {
+			assert.Contains(t, r.Categories, r.Form(n), "%s: %d", l, n)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package plural
+
+// rules maps lowercase CLDR locale ids to rules, restricted to integer counts (CLDR operands v, f and t are 0, and
+// n == i). Based on CLDR 44.
+var rules = make(map[string]*Rule)
+
+func init() {
+	add(other, "bm", "bo", "dz", "id", "ig", "ii", "ja", "jv", "kea", "km", "ko", "lo", "ms", "my", "sah", "ses",
+		"sg", "su", "th", "to", "vi", "wo", "yo", "yue", "zh")
+	add(oneIfOne, "af", "az", "bg", "ce", "da", "de", "el", "en", "et", "eu", "fi", "fo", "fy", "gl", "ha", "hu",
+		"ka", "kk", "ky", "lb", "ml", "mn", "mr", "nb", "ne", "nl", "nn", "no", "or", "ps", "so", "sq", "sv", "sw",
+		"ta", "te", "tk", "tr", "ug", "ur", "uz")
+	add(oneIfZeroOrOne, "ak", "am", "as", "bn", "fa", "gu", "hi", "hy", "kn", "ln", "ti", "zu")
+	add(oneManyMillions(func(n uint64) bool { return n == 1 }), "es", "it", "ca", "pt-pt")
+	add(oneManyMillions(func(n uint64) bool { return n <= 1 }), "fr", "pt")
+	add(slavicEast, "ru", "uk", "be")
+	add(slavicSouth, "hr", "sr", "bs", "sh")
+	add(polish, "pl")
+	add(czech, "cs", "sk")
+	add(arabic, "ar")
+	add(hebrew, "he", "iw")
+	add(irish, "ga")
+	add(welsh, "cy")
+	add(lithuanian, "lt")
+	add(latvian, "lv")
+	add(romanian, "ro", "mo")
+	add(slovenian, "sl")
+	add(icelandic, "is", "mk")
+	add(filipino, "fil", "tl")
+	add(scottish, "gd")
+	add(maltese, "mt")
+}
+
+func add(r *Rule, locales ...string) {
+	for _, l := range locales {
+		rules[l] = r
+	}
+}
+
+func in(n, lo, hi uint64) bool { return n >= lo && n <= hi }
+
+var (
+	other = &Rule{[]Category{Other}, func(uint64) Category { return Other }}
+
+	oneIfOne = &Rule{[]Category{One, Other}, func(n uint64) Category {
+		if n == 1 {
+			return One
+		}
+		return Other
+	}}
+
+	oneIfZeroOrOne = &Rule{[]Category{One, Other}, func(n uint64) Category {
+		if n <= 1 {
+			return One
+		}
+		return Other
+	}}
+
+	slavicEast = &Rule{[]Category{One, Few, Many, Other}, func(n uint64) Category {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return One
+		case in(n%10, 2, 4) && !in(n%100, 12, 14):
+			return Few
+		default:
+			return Many // fractions are other
+		}
+	}}
+
+	slavicSouth = &Rule{[]Category{One, Few, Other}, func(n uint64) Category {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return One
+		case in(n%10, 2, 4) && !in(n%100, 12, 14):
+			return Few
+		default:
+			return Other
+		}
+	}}
+
+	polish = &Rule{[]Category{One, Few, Many, Other}, func(n uint64) Category {
+		switch {
+		case n == 1:
+			return One
+		case in(n%10, 2, 4) && !in(n%100, 12, 14):
+			return Few
+		default:
+			return Many // fractions are other
+		}
+	}}
+
+	czech = &Rule{[]Category{One, Few, Many, Other}, func(n uint64) Category {
+		switch {
+		case n == 1:
+			return One
+		case in(n, 2, 4):
+			return Few
+		default:
+			return Other // many is for fractions
+		}
+	}}
+
+	arabic = &Rule{[]Category{Zero, One, Two, Few, Many, Other}, func(n uint64) Category {
+		switch {
+		case n == 0:
+			return Zero
+		case n == 1:
+			return One
+		case n == 2:
+			return Two
+		case in(n%100, 3, 10):
+			return Few
+		case in(n%100, 11, 99):
+			return Many
+		default:
+			return Other
+		}
+	}}
+
+	hebrew = &Rule{[]Category{One, Two, Other}, func(n uint64) Category {
+		switch n {
+		case 1:
+			return One
+		case 2:
+			return Two
+		default:
+			return Other
+		}
+	}}
+
+	irish = &Rule{[]Category{One, Two, Few, Many, Other}, func(n uint64) Category {
+		switch {
+		case n == 1:
+			return One
+		case n == 2:
+			return Two
+		case in(n, 3, 6):
+			return Few
+		case in(n, 7, 10):
+			return Many
+		default:
+			return Other
+		}
+	}}
+
+	welsh = &Rule{[]Category{Zero, One, Two, Few, Many, Other}, func(n uint64) Category {
+		switch n {
+		case 0:
+			return Zero
+		case 1:
+			return One
+		case 2:
+			return Two
+		case 3:
+			return Few
+		case 6:
+			return Many
+		default:
+			return Other
+		}
+	}}
+
+	lithuanian = &Rule{[]Category{One, Few, Many, Other}, func(n uint64) Category {
+		switch {
+		case n%10 == 1 && !in(n%100, 11, 19):
+			return One
+		case in(n%10, 2, 9) && !in(n%100, 11, 19):
+			return Few
+		default:
+			return Other // many is for fractions
+		}
+	}}
+
+	latvian = &Rule{[]Category{Zero, One, Other}, func(n uint64) Category {
+		switch {
+		case n%10 == 0 || in(n%100, 11, 19):
+			return Zero
+		case n%10 == 1 && n%100 != 11:
+			return One
+		default:
+			return Other
+		}
+	}}
+
+	romanian = &Rule{[]Category{One, Few, Other}, func(n uint64) Category {
+		switch {
+		case n == 1:
+			return One
+		case n == 0 || in(n%100, 1, 19):
+			return Few
+		default:
+			return Other
+		}
+	}}
+
+	slovenian = &Rule{[]Category{One, Two, Few, Other}, func(n uint64) Category {
+		switch {
+		case n%100 == 1:
+			return One
+		case n%100 == 2:
+			return Two
+		case in(n%100, 3, 4):
+			return Few
+		default:
+			return Other
+		}
+	}}
+
+	icelandic = &Rule{[]Category{One, Other}, func(n uint64) Category {
+		if n%10 == 1 && n%100 != 11 {
+			return One
+		}
+		return Other
+	}}
+
+	filipino = &Rule{[]Category{One, Other}, func(n uint64) Category {
+		if d := n % 10; d != 4 && d != 6 && d != 9 {
+			return One
+		}
+		return Other
+	}}
+
+	scottish = &Rule{[]Category{One, Two, Few, Other}, func(n uint64) Category {
+		switch {
+		case n == 1 || n == 11:
+			return One
+		case n == 2 || n == 12:
+			return Two
+		case in(n, 3, 10) || in(n, 13, 19):
+			return Few
+		default:
+			return Other
+		}
+	}}
+
+	maltese = &Rule{[]Category{One, Two, Few, Many, Other}, func(n uint64) Category {
+		switch {
+		case n == 1:
+			return One
+		case n == 2:
+			return Two
+		case n == 0 || in(n%100, 3, 10):
+			return Few
+		case in(n%100, 11, 19):
+			return Many
+		default:
+			return Other
+		}
+	}}
+)
+
+// oneManyMillions is the rule for Romance languages, which use many for exact multiples of a million
+// ("un million de fichiers")
+func oneManyMillions(one func(n uint64) bool) *Rule {
+	return &Rule{[]Category{One, Many, Other}, func(n uint64) Category {
+		switch {
+		case one(n):
+			return One
+		case n != 0 && n%1000000 == 0:
+			return Many
+		default:
+			return Other
+		}
+	}}
+}
@@ -0,0 +1,191 @@
+package po
+
+import (
+	"strconv"
+	"strings"
+)
+
+// fuzzyThreshold is the similarity above which a translation of a changed message is reused, as by msgmerge
+const fuzzyThreshold = 0.6
+
+// Merge updates the translations in def for a new template ref, in the manner of msgmerge, returning the result:
+//   - messages present in both keep def's translation and translator comments, taking their extracted comments,
+//     references and flags from ref. If the plural form changed, the translation is marked fuzzy.
+//   - messages only in ref are new. If def has a translated message in the same context whose msgid is similar
+//     enough, the message has probably changed: its translation is reused, marked fuzzy, and the previous msgid
+//     recorded for the translator.
+//   - messages only in def are kept as obsolete, after the others.
+//
+// def's header is kept. Neither def nor ref is modified.
+func Merge(def, ref *File) *File {
+	out := &File{
+		HeaderComments: def.HeaderComments,
+		HeaderFlags:    def.HeaderFlags,
+		Header:         def.Header,
+	}
+	if out.Header == nil {
+		out.HeaderComments, out.HeaderFlags, out.Header = ref.HeaderComments, ref.HeaderFlags, ref.Header
+	}
+	nplurals := out.Header.NPlurals()
+
+	// current messages take precedence over obsolete ones, which are revived if they return
+	old := make(map[string]*Message)
+	for _, m := range def.Messages {
+		if prev, ok := old[m.Key()]; !ok || (prev.Obsolete && !m.Obsolete) {
+			old[m.Key()] = m
+		}
+	}
+	used := make(map[*Message]bool)
+
+	for _, r := range ref.Messages {
+		if r.Obsolete {
+			continue
+		}
+		m := &Message{
+			ExtractedComments: r.ExtractedComments,
+			References:        r.References,
+			Flags:             withoutFlag(r.Flags, FlagFuzzy),
+			Ctx:               r.Ctx,
+			ID:                r.ID,
+			IDPlural:          r.IDPlural,
+		}
+		if d, ok := old[r.Key()]; ok {
+			used[d] = true
+			m.TranslatorComments = d.TranslatorComments
+			m.Str = convertForms(d, r, nplurals)
+			if d.Fuzzy() {
+				m.SetFuzzy(true)
+				m.PrevCtx, m.PrevID, m.PrevIDPlural = d.PrevCtx, d.PrevID, d.PrevIDPlural
+			}
+			if d.IDPlural != r.IDPlural && hasTranslation(d) {
+				m.SetFuzzy(true)
+				m.PrevID, m.PrevIDPlural = d.ID, d.IDPlural
+			}
+		} else if d := similar(def, r); d != nil {
+			m.TranslatorComments = d.TranslatorComments
+			m.Str = convertForms(d, r, nplurals)
+			m.SetFuzzy(true)
+			m.PrevCtx, m.PrevID, m.PrevIDPlural = d.Ctx, d.ID, d.IDPlural
+		} else {
+			m.Str = emptyForms(r, nplurals)
+		}
+		out.Messages = append(out.Messages, m)
+	}
+
+	for _, d := range def.Messages {
+		if used[d] {
+			continue
+		}
+		if d.Obsolete && old[d.Key()] != d {
+			continue // duplicate
+		}
+		if !d.Translated() && !d.Fuzzy() {
+			continue // nothing worth keeping
+		}
+		o := *d
+		o.Obsolete = true
+		o.References = nil
+		out.Messages = append(out.Messages, &o)
+	}
+	return out
+}
+
+// similar returns the translated message in def most similar to r, if any is similar enough
+func similar(def *File, r *Message) *Message {
+	var best *Message
+	bestScore := fuzzyThreshold
+	for _, d := range def.Messages {
+		if d.Ctx != r.Ctx || !hasTranslation(d) {
+			continue
+		}
+		if score := similarity(d.ID, r.ID); score >= bestScore {
+			best, bestScore = d, score
+		}
+	}
+	return best
+}
+
+func hasTranslation(m *Message) bool {
+	for _, s := range m.Str {
+		if s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// similarity returns 1 for identical strings, decreasing towards 0 with their edit distance
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	diff := len(ra) - len(rb)
+	if diff < 0 {
+		diff = -diff
+	}
+	if 1-float64(diff)/float64(longest) < fuzzyThreshold {
+		return 0 // the distance is at least the difference in length
+	}
+	// Levenshtein distance, one row at a time
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		diag := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			next := min(row[j]+1, row[j-1]+1, diag+cost)
+			diag, row[j] = row[j], next
+		}
+	}
+	return 1 - float64(row[len(rb)])/float64(longest)
+}
+
+// convertForms returns the translation of d for the forms of r, which may differ in being plural
+func convertForms(d, r *Message, nplurals int) []string {
+	str := append([]string(nil), d.Str...)
+	switch {
+	case len(str) == 0:
+		return emptyForms(r, nplurals)
+	case r.IsPlural() && !d.IsPlural():
+		// the singular translation serves for each form until the translator reviews it
+		for len(str) < nplurals {
+			str = append(str, str[0])
+		}
+	case !r.IsPlural() && d.IsPlural():
+		str = str[:1]
+	}
+	return str
+}
+
+// emptyForms returns empty translations for r
+func emptyForms(r *Message, nplurals int) []string {
+	if r.IsPlural() {
+		return make([]string, nplurals)
+	}
+	return []string{""}
+}
+
+func withoutFlag(flags []string, flag string) []string {
+	return setFlag(flags, flag, false)
+}
+
+// NPlurals returns the number of plural forms given by the Plural-Forms field, or 2 if it is absent or invalid
+func (h Header) NPlurals() int {
+	for _, part := range strings.Split(h.Get("Plural-Forms"), ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && strings.TrimSpace(name) == "nplurals" {
+			if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 2
+}
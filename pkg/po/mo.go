@@ -0,0 +1,121 @@
+package po
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// magic number of .mo files, in the byte order of the machine which wrote them
+const moMagic = 0x950412de
+
+// ErrNotMO is returned by ReadMO for data which is not a .mo file
+var ErrNotMO = errors.New("not a .mo file")
+
+// ReadMO parses a compiled .mo file, as written by msgfmt. Only translated messages are present in .mo files, and
+// they carry no comments or references.
+func ReadMO(data []byte) (*File, error) {
+	if len(data) < 28 {
+		return nil, ErrNotMO
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(data) != moMagic {
+		order = binary.BigEndian
+		if order.Uint32(data) != moMagic {
+			return nil, ErrNotMO
+		}
+	}
+	if rev := order.Uint32(data[4:]); rev>>16 > 1 {
+		return nil, fmt.Errorf("unsupported .mo revision %d", rev>>16)
+	}
+	count := int(order.Uint32(data[8:]))
+	origTable, transTable := int(order.Uint32(data[12:])), int(order.Uint32(data[16:]))
+
+	// str returns the i'th string in the table at offset
+	str := func(table, i int) (string, error) {
+		entry := table + 8*i
+		if entry < 0 || entry+8 > len(data) {
+			return "", fmt.Errorf("string table entry %d out of range", i)
+		}
+		length, offset := int(order.Uint32(data[entry:])), int(order.Uint32(data[entry+4:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return "", fmt.Errorf("string %d out of range", i)
+		}
+		return string(data[offset : offset+length]), nil
+	}
+
+	f := new(File)
+	for i := 0; i < count; i++ {
+		orig, err := str(origTable, i)
+		if err != nil {
+			return nil, err
+		}
+		trans, err := str(transTable, i)
+		if err != nil {
+			return nil, err
+		}
+		m := new(Message)
+		if ctx, id, ok := strings.Cut(orig, "\x04"); ok {
+			m.Ctx, orig = ctx, id
+		}
+		m.ID, m.IDPlural, _ = strings.Cut(orig, "\x00")
+		m.Str = strings.Split(trans, "\x00")
+		if m.ID == "" && m.Ctx == "" {
+			f.Header = parseHeader(trans)
+			continue
+		}
+		f.Messages = append(f.Messages, m)
+	}
+	return f, nil
+}
+
+// WriteMO writes the translated messages in compiled .mo format, as msgfmt does. Fuzzy, untranslated and
+// obsolete messages are omitted. The hash table is omitted, which readers treat as absent.
+func (f *File) WriteMO(w io.Writer) error {
+	type entry struct{ orig, trans string }
+	entries := []entry{{"", f.Header.String()}}
+	for _, m := range f.Messages {
+		if m.Obsolete || !m.Translated() {
+			continue
+		}
+		orig := m.Key()
+		if m.IsPlural() {
+			orig += "\x00" + m.IDPlural
+		}
+		entries = append(entries, entry{orig, strings.Join(m.Str, "\x00")})
+	}
+	// readers binary search the original strings
+	sort.Slice(entries, func(i, j int) bool { return entries[i].orig < entries[j].orig })
+
+	const headerSize = 28
+	n := len(entries)
+	origTable := headerSize
+	transTable := origTable + 8*n
+	offset := transTable + 8*n
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	for _, v := range []uint32{moMagic, 0, uint32(n), uint32(origTable), uint32(transTable), 0, uint32(offset)} {
+		binary.Write(&buf, le, v)
+	}
+	var strs bytes.Buffer
+	table := func(s string) {
+		binary.Write(&buf, le, uint32(len(s)))
+		binary.Write(&buf, le, uint32(offset+strs.Len()))
+		strs.WriteString(s)
+		strs.WriteByte(0)
+	}
+	for _, e := range entries {
+		table(e.orig)
+	}
+	for _, e := range entries {
+		table(e.trans)
+	}
+	buf.Write(strs.Bytes())
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,265 @@
+package po
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PluralForms is a parsed Plural-Forms header, such as
+// "nplurals=2; plural=(n != 1);", which selects the msgstr[N] used for a count
+type PluralForms struct {
+	N    int // number of plural forms
+	expr pluralExpr
+}
+
+// Index returns the index of the plural form for the count n, in [0, N)
+func (p *PluralForms) Index(n int) int {
+	if n < 0 {
+		n = -n
+	}
+	i := p.expr.eval(int64(n))
+	if i < 0 || i >= int64(p.N) {
+		return 0
+	}
+	return int(i)
+}
+
+// PluralForms parses the Plural-Forms field. Without the field, the rule is that of English.
+func (h Header) PluralForms() (*PluralForms, error) {
+	s := h.Get("Plural-Forms")
+	if s == "" {
+		s = "nplurals=2; plural=(n != 1);"
+	}
+	return ParsePluralForms(s)
+}
+
+// ParsePluralForms parses the value of a Plural-Forms header. The plural expression is in C syntax, using the
+// variable n.
+func ParsePluralForms(s string) (*PluralForms, error) {
+	p := &PluralForms{N: -1}
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "nplurals":
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("plural forms %q: invalid nplurals", s)
+			}
+			p.N = n
+		case "plural":
+			e := &exprParser{s: value}
+			expr, err := e.parse()
+			if err != nil {
+				return nil, fmt.Errorf("plural forms %q: %w", s, err)
+			}
+			p.expr = expr
+		}
+	}
+	if p.N < 0 || p.expr == nil {
+		return nil, fmt.Errorf("plural forms %q: expected nplurals and plural", s)
+	}
+	return p, nil
+}
+
+// pluralExpr is a node of a parsed plural expression
+type pluralExpr interface {
+	eval(n int64) int64
+}
+
+type (
+	varExpr   struct{}
+	constExpr int64
+	notExpr   struct{ x pluralExpr }
+	binExpr   struct {
+		op   string
+		x, y pluralExpr
+	}
+	condExpr struct{ cond, x, y pluralExpr }
+)
+
+func (varExpr) eval(n int64) int64   { return n }
+func (c constExpr) eval(int64) int64 { return int64(c) }
+func (e notExpr) eval(n int64) int64 { return b2i(e.x.eval(n) == 0) }
+
+func (e condExpr) eval(n int64) int64 {
+	if e.cond.eval(n) != 0 {
+		return e.x.eval(n)
+	}
+	return e.y.eval(n)
+}
+
+func (e binExpr) eval(n int64) int64 {
+	x := e.x.eval(n)
+	switch e.op {
+	case "||":
+		return b2i(x != 0 || e.y.eval(n) != 0)
+	case "&&":
+		return b2i(x != 0 && e.y.eval(n) != 0)
+	}
+	y := e.y.eval(n)
+	switch e.op {
+	case "==":
+		return b2i(x == y)
+	case "!=":
+		return b2i(x != y)
+	case "<":
+		return b2i(x < y)
+	case "<=":
+		return b2i(x <= y)
+	case ">":
+		return b2i(x > y)
+	case ">=":
+		return b2i(x >= y)
+	case "+":
+		return x + y
+	case "-":
+		return x - y
+	case "*":
+		return x * y
+	case "/", "%":
+		if y == 0 {
+			return 0
+		}
+		if e.op == "/" {
+			return x / y
+		}
+		return x % y
+	}
+	panic("unknown operator " + e.op)
+}
+
+func b2i(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// binary operators by precedence, lowest first. Longer operators come first within a level, so that "<=" is not
+// read as "<".
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// exprParser is a recursive descent parser for the C expressions used by Plural-Forms
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) parse() (pluralExpr, error) {
+	e, err := p.cond()
+	if err != nil {
+		return nil, err
+	}
+	if p.skip(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q", p.s[p.pos:])
+	}
+	return e, nil
+}
+
+func (p *exprParser) skip() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+// accept consumes tok if it is next
+func (p *exprParser) accept(tok string) bool {
+	p.skip()
+	if strings.HasPrefix(p.s[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) cond() (pluralExpr, error) {
+	c, err := p.binary(0)
+	if err != nil || !p.accept("?") {
+		return c, err
+	}
+	x, err := p.cond()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(":") {
+		return nil, fmt.Errorf("expected ':' at %d", p.pos)
+	}
+	y, err := p.cond()
+	if err != nil {
+		return nil, err
+	}
+	return condExpr{c, x, y}, nil
+}
+
+func (p *exprParser) binary(level int) (pluralExpr, error) {
+	if level == len(precedence) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, o := range precedence[level] {
+			if p.accept(o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return x, nil
+		}
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = binExpr{op, x, y}
+	}
+}
+
+func (p *exprParser) unary() (pluralExpr, error) {
+	if p.accept("!") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	}
+	if p.accept("(") {
+		x, err := p.cond()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("expected ')' at %d", p.pos)
+		}
+		return x, nil
+	}
+	if p.accept("n") {
+		return varExpr{}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, fmt.Errorf("unexpected %q", p.s[p.pos:])
+	}
+	v, err := strconv.ParseInt(p.s[start:p.pos], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return constExpr(v), nil
+}
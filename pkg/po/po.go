@@ -0,0 +1,178 @@
+// Package po reads and writes gettext .po and .pot files, and merges
+// translations into an updated template in the manner of msgmerge.
+//
+// See https://www.gnu.org/software/gettext/manual/html_node/PO-Files.html
+package po
+
+import "strings"
+
+// File is the content of a .po or .pot file
+type File struct {
+	// comments preceding the header entry, such as a copyright notice
+	HeaderComments []string
+	// flags of the header entry; a new template's header is fuzzy
+	HeaderFlags []string
+	// header fields, from the msgstr of the entry with an empty msgid
+	Header Header
+	// messages, in file order; includes obsolete messages
+	Messages []*Message
+}
+
+// Message is an entry in a .po file
+type Message struct {
+	TranslatorComments []string // "# " lines, written by translators
+	ExtractedComments  []string // "#." lines, comments from the source for translators
+	References         []string // "#:" lines, places the message is used as file:line
+	Flags              []string // "#," lines, such as "fuzzy" or "c-format"
+
+	// previous message, "#|" lines; set on fuzzy messages whose msgid changed
+	PrevCtx, PrevID, PrevIDPlural string
+
+	Ctx      string   // msgctxt; empty if absent
+	ID       string   // msgid
+	IDPlural string   // msgid_plural; empty unless the message is plural
+	Str      []string // msgstr, or msgstr[N] for each plural form
+
+	// obsolete messages are kept as "#~" lines, so their translations can be reused
+	Obsolete bool
+}
+
+// FlagFuzzy marks messages whose translation needs review
+const FlagFuzzy = "fuzzy"
+
+// Key identifies a message by its context and msgid, as in compiled .mo files
+func (m *Message) Key() string {
+	return Key(m.Ctx, m.ID)
+}
+
+// Key returns the key of a message with the given context and msgid
+func Key(ctx, id string) string {
+	if ctx == "" {
+		return id
+	}
+	return ctx + "\x04" + id
+}
+
+// IsPlural reports whether the message has plural forms
+func (m *Message) IsPlural() bool { return m.IDPlural != "" }
+
+// HasFlag reports whether the message has the given flag
+func (m *Message) HasFlag(flag string) bool {
+	for _, f := range m.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Fuzzy reports whether the message's translation needs review
+func (m *Message) Fuzzy() bool { return m.HasFlag(FlagFuzzy) }
+
+// SetFuzzy adds or removes the fuzzy flag
+func (m *Message) SetFuzzy(fuzzy bool) {
+	m.Flags = setFlag(m.Flags, FlagFuzzy, fuzzy)
+}
+
+func setFlag(flags []string, flag string, set bool) []string {
+	var out []string
+	for _, f := range flags {
+		if f != flag {
+			out = append(out, f)
+		}
+	}
+	if set {
+		// fuzzy conventionally comes first
+		out = append([]string{flag}, out...)
+	}
+	return out
+}
+
+// Translated reports whether the message has a translation in every form, which is not fuzzy
+func (m *Message) Translated() bool {
+	if m.Fuzzy() || len(m.Str) == 0 {
+		return false
+	}
+	for _, s := range m.Str {
+		if s == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Lookup returns the message with the given key which is not obsolete, or nil
+func (f *File) Lookup(key string) *Message {
+	for _, m := range f.Messages {
+		if !m.Obsolete && m.Key() == key {
+			return m
+		}
+	}
+	return nil
+}
+
+// Header is the list of fields held in a .po file's header entry, such as
+// "Content-Type: text/plain; charset=UTF-8"
+type Header []HeaderField
+
+// HeaderField is a field of a .po header
+type HeaderField struct {
+	Name, Value string
+}
+
+// Get returns the value of the named field, or "" if absent. Names are case-insensitive.
+func (h Header) Get(name string) string {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Set sets the value of the named field, adding it if absent
+func (h *Header) Set(name, value string) {
+	for i, f := range *h {
+		if strings.EqualFold(f.Name, name) {
+			(*h)[i].Value = value
+			return
+		}
+	}
+	*h = append(*h, HeaderField{name, value})
+}
+
+// parseHeader parses the msgstr of a header entry
+func parseHeader(s string) Header {
+	var h Header
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(line, ":")
+		h = append(h, HeaderField{strings.TrimSpace(name), strings.TrimSpace(value)})
+	}
+	return h
+}
+
+func (h Header) String() string {
+	var b strings.Builder
+	for _, f := range h {
+		b.WriteString(f.Name + ": " + f.Value + "\n")
+	}
+	return b.String()
+}
+
+// NewTemplate returns a .pot file with the usual header fields, to which messages may be added
+func NewTemplate() *File {
+	return &File{
+		HeaderFlags: []string{FlagFuzzy},
+		Header: Header{
+			{"Project-Id-Version", "PACKAGE VERSION"},
+			{"Language", ""},
+			{"MIME-Version", "1.0"},
+			{"Content-Type", "text/plain; charset=UTF-8"},
+			{"Content-Transfer-Encoding", "8bit"},
+			{"Plural-Forms", "nplurals=INTEGER; plural=EXPRESSION;"},
+		},
+	}
+}
@@ -0,0 +1,204 @@
+package po
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const de = `# German translations
+#, fuzzy
+msgid ""
+msgstr ""
+"Language: de\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+# Thomas prefers this
+#. greeting shown on startup
+#: main.go:12 main.go:20
+msgid "Hello, \"World\"!"
+msgstr "Hallo, \"Welt\"!"
+
+#: main.go:14
+msgctxt "verb"
+msgid "Open"
+msgstr "Öffnen"
+
+#: main.go:15
+#, fuzzy, c-format
+#| msgid "%d file"
+msgid "%d file deleted"
+msgid_plural "%d files deleted"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+
+msgid ""
+"two\n"
+"lines\n"
+msgstr ""
+"zwei\n"
+"Zeilen\n"
+
+#~ msgid "Gone"
+#~ msgstr "Weg"
+`
+
+func TestReadWrite(t *testing.T) {
+	f, err := ReadBytes([]byte(de))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"German translations"}, f.HeaderComments)
+	assert.Equal(t, "de", f.Header.Get("language"))
+	assert.Equal(t, 2, f.Header.NPlurals())
+	require.Len(t, f.Messages, 5)
+
+	hello := f.Messages[0]
+	assert.Equal(t, `Hello, "World"!`, hello.ID)
+	assert.Equal(t, []string{`Hallo, "Welt"!`}, hello.Str)
+	assert.Equal(t, []string{"Thomas prefers this"}, hello.TranslatorComments)
+	assert.Equal(t, []string{"greeting shown on startup"}, hello.ExtractedComments)
+	assert.Equal(t, []string{"main.go:12", "main.go:20"}, hello.References)
+	assert.True(t, hello.Translated())
+
+	assert.Equal(t, "verb\x04Open", f.Messages[1].Key())
+	assert.NotNil(t, f.Lookup("verb\x04Open"))
+	assert.Nil(t, f.Lookup("Open"))
+
+	deleted := f.Messages[2]
+	assert.True(t, deleted.IsPlural())
+	assert.True(t, deleted.Fuzzy())
+	assert.False(t, deleted.Translated())
+	assert.Equal(t, "%d file", deleted.PrevID)
+	assert.Equal(t, []string{"%d Datei", "%d Dateien"}, deleted.Str)
+
+	assert.Equal(t, "two\nlines\n", f.Messages[3].ID)
+	assert.True(t, f.Messages[4].Obsolete)
+	assert.Nil(t, f.Lookup("Gone"), "obsolete")
+
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+	assert.Equal(t, de, buf.String(), "must round-trip")
+}
+
+func TestReadInvalid(t *testing.T) {
+	for _, s := range []string{
+		`msgid "no msgstr"`,
+		`msgid "x" msgstr "y"`,
+		"msgid \"x\"\nmsgstr \"\\q\"",
+		"msgid \"x\"\nmsgstr[1] \"y\"",
+		"\"orphan\"",
+	} {
+		_, err := ReadBytes([]byte(s))
+		assert.Error(t, err, s)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	def, err := ReadBytes([]byte(de))
+	require.NoError(t, err)
+
+	ref := NewTemplate()
+	ref.Messages = []*Message{
+		{ID: `Hello, "World"!`, References: []string{"main.go:30"}, ExtractedComments: []string{"new comment"}},
+		{Ctx: "verb", ID: "Open"},
+		{ID: "Open"},
+		{ID: "%d file deleted", IDPlural: "%d files were deleted"},
+		{ID: "Hello, \"World\"!!"},
+		{ID: "Gone"},
+	}
+	m := Merge(def, ref)
+	assert.Equal(t, def.Header, m.Header, "existing header is kept")
+	require.Len(t, m.Messages, 7)
+
+	hello := m.Messages[0]
+	assert.Equal(t, []string{`Hallo, "Welt"!`}, hello.Str, "translation kept")
+	assert.Equal(t, []string{"Thomas prefers this"}, hello.TranslatorComments)
+	assert.Equal(t, []string{"new comment"}, hello.ExtractedComments, "updated from template")
+	assert.Equal(t, []string{"main.go:30"}, hello.References, "updated from template")
+	assert.True(t, hello.Translated())
+
+	assert.Equal(t, []string{"Öffnen"}, m.Messages[1].Str)
+	assert.Equal(t, []string{""}, m.Messages[2].Str, "context differs")
+	assert.False(t, m.Messages[2].Fuzzy(), "no similar message in the same context")
+
+	deleted := m.Messages[3]
+	assert.True(t, deleted.Fuzzy(), "plural changed")
+	assert.Equal(t, "%d files deleted", deleted.PrevIDPlural)
+
+	changed := m.Messages[4]
+	assert.True(t, changed.Fuzzy(), "similar message")
+	assert.Equal(t, `Hello, "World"!`, changed.PrevID)
+	assert.Equal(t, []string{`Hallo, "Welt"!`}, changed.Str)
+
+	gone := m.Messages[5]
+	assert.False(t, gone.Obsolete, "revived")
+	assert.Equal(t, []string{"Weg"}, gone.Str)
+
+	obsolete := m.Messages[6]
+	assert.True(t, obsolete.Obsolete)
+	assert.Equal(t, "two\nlines\n", obsolete.ID)
+
+	assert.Len(t, def.Messages, 5, "def must not be modified")
+	assert.False(t, def.Messages[4].Fuzzy())
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, similarity("abc", "abc"))
+	assert.Equal(t, 0.0, similarity("a", "abcdef"))
+	assert.InDelta(t, 0.75, similarity("abcd", "abce"), 0.001)
+}
+
+func TestPluralForms(t *testing.T) {
+	for _, tc := range []struct {
+		forms string
+		want  map[int]int
+	}{
+		{"nplurals=1; plural=0;", map[int]int{0: 0, 1: 0, 2: 0}},
+		{"nplurals=2; plural=(n != 1);", map[int]int{0: 1, 1: 0, 2: 1}},
+		{"nplurals=2; plural=n>1;", map[int]int{0: 0, 1: 0, 2: 1}},
+		{"nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);",
+			map[int]int{1: 0, 2: 1, 5: 2, 11: 2, 21: 0, 22: 1, 112: 2}},
+		{"nplurals=6; plural=n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5;",
+			map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 11: 4, 100: 5}},
+		{"nplurals=2; plural=!(n == 1);", map[int]int{1: 0, 2: 1}},
+		{"nplurals=2; plural=n+5;", map[int]int{0: 0}},
+	} {
+		p, err := ParsePluralForms(tc.forms)
+		require.NoError(t, err, tc.forms)
+		for n, want := range tc.want {
+			assert.Equal(t, want, p.Index(n), "%s: %d", tc.forms, n)
+		}
+	}
+
+	for _, s := range []string{"nplurals=2;", "plural=n!=1;", "nplurals=2; plural=n ! 1;", "nplurals=2; plural=(n;"} {
+		_, err := ParsePluralForms(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestMO(t *testing.T) {
+	f, err := ReadBytes([]byte(de))
+	require.NoError(t, err)
+	f.Messages[2].SetFuzzy(false)
+
+	var buf bytes.Buffer
+	require.NoError(t, f.WriteMO(&buf))
+	mo, err := ReadMO(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, f.Header, mo.Header)
+	require.Len(t, mo.Messages, 4, "obsolete message omitted")
+
+	byKey := make(map[string]*Message)
+	for _, m := range mo.Messages {
+		byKey[m.Key()] = m
+	}
+	assert.Equal(t, []string{`Hallo, "Welt"!`}, byKey[`Hello, "World"!`].Str)
+	assert.Equal(t, []string{"Öffnen"}, byKey["verb\x04Open"].Str)
+	assert.Equal(t, "%d files deleted", byKey["%d file deleted"].IDPlural)
+	assert.Equal(t, []string{"%d Datei", "%d Dateien"}, byKey["%d file deleted"].Str)
+
+	_, err = ReadMO([]byte("msgid \"\"\nmsgstr \"\"\n"))
+	assert.Equal(t, ErrNotMO, err)
+}
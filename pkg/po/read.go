@@ -0,0 +1,267 @@
+package po
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Read parses a .po or .pot file
+func Read(r io.Reader) (*File, error) {
+	p := &parser{file: new(File)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		p.line++
+		if err := p.parseLine(strings.TrimSpace(scanner.Text())); err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := p.flush(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.line, err)
+	}
+	return p.file, nil
+}
+
+// ReadBytes parses a .po or .pot file held in memory
+func ReadBytes(data []byte) (*File, error) {
+	return Read(bytes.NewReader(data))
+}
+
+type parser struct {
+	file *File
+	line int
+
+	msg *Message // entry being parsed, or nil between entries
+	// the string which continuation lines are appended to
+	cur *string
+	// whether the entry has a msgstr, so that anything but another msgstr starts a new entry
+	hasStr bool
+}
+
+func (p *parser) parseLine(line string) error {
+	if line == "" {
+		return p.flush()
+	}
+	obsolete := false
+	if strings.HasPrefix(line, "#~") {
+		obsolete = true
+		line = strings.TrimSpace(line[2:])
+		if line == "" {
+			return nil
+		}
+		if strings.HasPrefix(line, "|") {
+			// "#~| msgid", the previous msgid of an obsolete message
+			line = "#" + line
+		}
+	}
+
+	if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#|") {
+		// comments begin a new entry if the current one is complete
+		if p.hasStr {
+			if err := p.flush(); err != nil {
+				return err
+			}
+		}
+		m := p.entry()
+		kind, text := line[:min(2, len(line))], strings.TrimSpace(line[min(2, len(line)):])
+		switch kind {
+		case "#.":
+			m.ExtractedComments = append(m.ExtractedComments, text)
+		case "#:":
+			m.References = append(m.References, strings.Fields(text)...)
+		case "#,":
+			for _, f := range strings.Split(text, ",") {
+				if f = strings.TrimSpace(f); f != "" {
+					m.Flags = append(m.Flags, f)
+				}
+			}
+		default:
+			// "# comment", or "#comment" which gettext also accepts
+			text = strings.TrimPrefix(line[1:], " ")
+			m.TranslatorComments = append(m.TranslatorComments, text)
+		}
+		return nil
+	}
+
+	prev := false
+	if strings.HasPrefix(line, "#|") {
+		prev = true
+		line = strings.TrimSpace(line[2:])
+	}
+	if strings.HasPrefix(line, `"`) {
+		if p.cur == nil {
+			return fmt.Errorf("unexpected string %s", line)
+		}
+		s, err := unquote(line)
+		if err != nil {
+			return err
+		}
+		*p.cur += s
+		return nil
+	}
+
+	keyword, value, ok := strings.Cut(line, " ")
+	if !ok {
+		return fmt.Errorf("expected keyword and string: %s", line)
+	}
+	s, err := unquote(strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	if p.hasStr && (prev || !strings.HasPrefix(keyword, "msgstr")) {
+		// a new entry without a blank line
+		if err := p.flush(); err != nil {
+			return err
+		}
+	}
+	m := p.entry()
+	m.Obsolete = m.Obsolete || obsolete
+	if prev {
+		switch keyword {
+		case "msgctxt":
+			m.PrevCtx, p.cur = s, &m.PrevCtx
+		case "msgid":
+			m.PrevID, p.cur = s, &m.PrevID
+		case "msgid_plural":
+			m.PrevIDPlural, p.cur = s, &m.PrevIDPlural
+		default:
+			return fmt.Errorf("unexpected keyword %s in previous message", keyword)
+		}
+		return nil
+	}
+	switch {
+	case keyword == "msgctxt":
+		m.Ctx, p.cur = s, &m.Ctx
+	case keyword == "msgid":
+		m.ID, p.cur = s, &m.ID
+	case keyword == "msgid_plural":
+		m.IDPlural, p.cur = s, &m.IDPlural
+	case keyword == "msgstr":
+		m.Str = append(m.Str, s)
+		p.cur = &m.Str[len(m.Str)-1]
+		p.hasStr = true
+	case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+		n, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+		if err != nil || n != len(m.Str) {
+			return fmt.Errorf("unexpected %s", keyword)
+		}
+		m.Str = append(m.Str, s)
+		p.cur = &m.Str[len(m.Str)-1]
+		p.hasStr = true
+	default:
+		return fmt.Errorf("unknown keyword %s", keyword)
+	}
+	return nil
+}
+
+// entry returns the entry being parsed, starting a new one if needed
+func (p *parser) entry() *Message {
+	if p.msg == nil {
+		p.msg = new(Message)
+	}
+	return p.msg
+}
+
+// flush adds the entry being parsed to the file
+func (p *parser) flush() error {
+	m := p.msg
+	p.msg, p.cur, p.hasStr = nil, nil, false
+	if m == nil {
+		return nil
+	}
+	if len(m.Str) == 0 {
+		if m.ID == "" && !m.Obsolete {
+			// comments alone, such as at the end of a file
+			return nil
+		}
+		return fmt.Errorf("message %q has no msgstr", m.ID)
+	}
+	if m.ID == "" && m.Ctx == "" && !m.Obsolete && p.file.Header == nil && len(p.file.Messages) == 0 {
+		p.file.HeaderComments = m.TranslatorComments
+		p.file.HeaderFlags = m.Flags
+		p.file.Header = parseHeader(m.Str[0])
+		if p.file.Header == nil {
+			p.file.Header = Header{}
+		}
+		return nil
+	}
+	p.file.Messages = append(p.file.Messages, m)
+	return nil
+}
+
+// unquote decodes a C string literal, as used by gettext
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("expected quoted string: %s", s)
+	}
+	s = s[1 : len(s)-1]
+	if !strings.ContainsRune(s, '\\') {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", fmt.Errorf("trailing backslash in %q", s)
+		}
+		switch c = s[i]; c {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '\\', '"', '\'', '?':
+			b.WriteByte(c)
+		case 'x':
+			j := i + 1
+			for j < len(s) && j < i+3 && isHex(s[j]) {
+				j++
+			}
+			n, err := strconv.ParseUint(s[i+1:j], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape in %q", s)
+			}
+			b.WriteByte(byte(n))
+			i = j - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			n, err := strconv.ParseUint(s[i:j], 8, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape in %q", s)
+			}
+			b.WriteByte(byte(n))
+			i = j - 1
+		default:
+			return "", fmt.Errorf("unknown escape \\%c in %q", c, s)
+		}
+	}
+	return b.String(), nil
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
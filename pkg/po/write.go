@@ -0,0 +1,157 @@
+package po
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// refWidth is the width at which "#:" lines are wrapped, as by xgettext
+const refWidth = 79
+
+// Write writes the file in .po syntax. Strings are not wrapped, except after embedded newlines.
+func (f *File) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if f.Header != nil {
+		for _, c := range f.HeaderComments {
+			writeComment(bw, "#", c)
+		}
+		if len(f.HeaderFlags) > 0 {
+			fmt.Fprintf(bw, "#, %s\n", strings.Join(f.HeaderFlags, ", "))
+		}
+		writeString(bw, "", "msgid", "")
+		writeString(bw, "", "msgstr", f.Header.String())
+	}
+	for i, m := range f.Messages {
+		if i > 0 || f.Header != nil {
+			bw.WriteString("\n")
+		}
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (m *Message) write(w *bufio.Writer) {
+	for _, c := range m.TranslatorComments {
+		writeComment(w, "#", c)
+	}
+	for _, c := range m.ExtractedComments {
+		writeComment(w, "#.", c)
+	}
+	line := ""
+	for _, r := range m.References {
+		if line != "" && len(line)+1+len(r) > refWidth {
+			w.WriteString(line + "\n")
+			line = ""
+		}
+		if line == "" {
+			line = "#:"
+		}
+		line += " " + r
+	}
+	if line != "" {
+		w.WriteString(line + "\n")
+	}
+	if len(m.Flags) > 0 {
+		fmt.Fprintf(w, "#, %s\n", strings.Join(m.Flags, ", "))
+	}
+
+	prefix, prevPrefix := "", "#| "
+	if m.Obsolete {
+		prefix, prevPrefix = "#~ ", "#~| "
+	}
+	if m.PrevCtx != "" {
+		writeString(w, prevPrefix, "msgctxt", m.PrevCtx)
+	}
+	if m.PrevID != "" {
+		writeString(w, prevPrefix, "msgid", m.PrevID)
+	}
+	if m.PrevIDPlural != "" {
+		writeString(w, prevPrefix, "msgid_plural", m.PrevIDPlural)
+	}
+
+	if m.Ctx != "" {
+		writeString(w, prefix, "msgctxt", m.Ctx)
+	}
+	writeString(w, prefix, "msgid", m.ID)
+	if !m.IsPlural() {
+		str := ""
+		if len(m.Str) > 0 {
+			str = m.Str[0]
+		}
+		writeString(w, prefix, "msgstr", str)
+		return
+	}
+	writeString(w, prefix, "msgid_plural", m.IDPlural)
+	strs := m.Str
+	if len(strs) == 0 {
+		strs = []string{"", ""}
+	}
+	for i, s := range strs {
+		writeString(w, prefix, fmt.Sprintf("msgstr[%d]", i), s)
+	}
+}
+
+// writeComment writes a comment, one line per line of text
+func writeComment(w *bufio.Writer, kind, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			w.WriteString(kind + "\n")
+		} else {
+			w.WriteString(kind + " " + line + "\n")
+		}
+	}
+}
+
+// writeString writes a keyword and its string. Strings containing newlines other than at the end are split
+// after each newline, following an empty first line.
+func writeString(w *bufio.Writer, prefix, keyword, s string) {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		fmt.Fprintf(w, "%s%s %s\n", prefix, keyword, quote(s))
+		return
+	}
+	fmt.Fprintf(w, "%s%s \"\"\n", prefix, keyword)
+	for _, line := range lines {
+		fmt.Fprintf(w, "%s%s\n", prefix, quote(line))
+	}
+}
+
+// quote encodes s as a C string literal, as used by gettext
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\', '"':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\v':
+			b.WriteString(`\v`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\%03o`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -9,17 +9,17 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"gopkg.in/godo.v2/glob"
 )
 
-func init() {
-	// discard logger ouput by default
-	log.SetOutput(ioutil.Discard)
-}
+// Log is the debug log of xtract's packages, shown by xtract -v. It discards its output by default, and is kept
+// apart from the standard logger so that programs importing the packages, such as analysis drivers, keep theirs.
+var Log = log.New(ioutil.Discard, "", log.LstdFlags)
 
-const parserMode = parser.Mode(0) // default parsing
+const parserMode = parser.ParseComments // comments are extracted for translators
 
 var fileSet = token.NewFileSet()
 
@@ -47,15 +47,39 @@ func ParseGoFile(filename string) (*ast.File, error) {
 	return parser.ParseFile(fileSet, filename, src, parserMode)
 }
 
-// FilesFromPatterns generates a list of Go file matching the glob-style wildcard patterns. Both unit tests and
-// vendored files are omitted.
+// Position returns the position of a node parsed by ParseGoFile
+func Position(pos token.Pos) token.Position {
+	return fileSet.Position(pos)
+}
+
+// FilesFromPatterns generates a list of Go file matching the glob-style wildcard patterns. Files are in the
+// order of the first pattern matching them, and in order of path for each pattern. Both unit tests and vendored
+// files are omitted.
 func FilesFromPatterns(patterns ...string) ([]string, error) {
-	files := make(map[string]bool)
-	assets, _, err := glob.Glob(patterns)
+	assets, regexps, err := glob.Glob(patterns)
 	if err != nil {
 		return nil, err
 	}
+	// regexps holds each pattern in turn; rank each file by the first matching it
+	rank := make(map[string]int, len(assets))
+	for _, asset := range assets {
+		rank[asset.Path] = len(regexps)
+		for i, re := range regexps {
+			if !re.Negate && (re.Path == asset.Path || re.Regexp != nil && re.Regexp.MatchString(asset.Path)) {
+				rank[asset.Path] = i
+				break
+			}
+		}
+	}
+	sort.Slice(assets, func(i, j int) bool {
+		ri, rj := rank[assets[i].Path], rank[assets[j].Path]
+		if ri != rj {
+			return ri < rj
+		}
+		return assets[i].Path < assets[j].Path
+	})
 
+	var files []string
 	for _, asset := range assets {
 		if strings.Contains(asset.Path, "/vendor/") {
 			// skip vendor directory
@@ -66,13 +90,8 @@ func FilesFromPatterns(patterns ...string) ([]string, error) {
 			continue
 		}
 
-		log.Printf("found file: %s", asset.Path)
-		files[asset.Path] = true
-	}
-
-	uniqs := make([]string, 0, len(files))
-	for file := range files {
-		uniqs = append(uniqs, file)
+		Log.Printf("found file: %s", asset.Path)
+		files = append(files, asset.Path)
 	}
-	return uniqs, nil
+	return files, nil
 }
@@ -0,0 +1,142 @@
+package xlate
+
+import (
+	"path"
+	"strings"
+	"sync"
+)
+
+// assets are the languages found in a Bundle's bindata. They are not modified once found.
+type assets struct {
+	bindata Bindata
+
+	//the language T()'s input strings are in
+	defaultLanguage Lingua
+
+	//the languages found, the default first
+	available Linguas
+
+	// map from language name to locale, which must match asset name - for example
+	// {"English": "en-us",}
+	//      ==>   en-us.json
+	langAssetMap map[Lingua]Locale
+
+	// map from language name to full asset name, such as "en-us.json" or "de.po"
+	langAssets map[Lingua]string
+}
+
+// Bundle holds the languages of a set of assets, and loads the translations
+// of each once, on first use, into a Localizer for the language. Unlike the
+// package-level funcs, which translate to a single current language, a
+// Bundle serves any number of languages at once, such as those of the users
+// of a server, each request translating with the Localizer for its user. A
+// Bundle and its Localizers may be used from any number of goroutines.
+//
+// The package-level funcs use the DefaultBundle, loaded by Setup.
+type Bundle struct {
+	*assets
+
+	mu         sync.Mutex
+	localizers map[Lingua]*Localizer
+}
+
+// Localizer translates from the default language of a Bundle to one of its
+// languages. It is not modified once loaded, so may be shared between
+// goroutines.
+type Localizer struct {
+	*assets
+
+	lang Lingua
+
+	//maps from phrase in primary language to this one, preceded by its context and \x04 if it has one
+	translations map[string]string
+
+	//maps from singular phrase in primary language, keyed as for translations, to plural forms in this one
+	pluralTranslations map[string][]string
+
+	//returns the index in pluralTranslations of the form for a count
+	pluralIndex func(n int) int
+}
+
+// NewBundle finds the languages of the assets in bdata, in which phrases are
+// translated from defaultLang. See Bindata.
+//
+// Assets may be json from cmd/xtract, or gettext .po or .mo catalogs. The
+// language of a gettext catalog is named by its X-Native-Language-Name
+// header, or else its Language header. As the msgids of gettext catalogs are
+// in the default language, it needs no asset of its own when they are used.
+func NewBundle(defaultLang Lingua, bdata Bindata) (*Bundle, error) {
+	a := &assets{
+		bindata:         bdata,
+		defaultLanguage: defaultLang,
+		available:       Linguas{defaultLang},
+		langAssetMap:    make(map[Lingua]Locale),
+		langAssets:      make(map[Lingua]string),
+	}
+	gettext := false
+	for fname, loader := range bdata {
+		ext := path.Ext(fname)
+		if ext != ".json" && ext != ".po" && ext != ".mo" {
+			continue
+		}
+		var err error
+		var data []byte
+		var lname Lingua
+		//if name == "yo-da.json" {continue}
+		if data, err = loader(); err != nil {
+			return nil, err
+		}
+		if ext == ".json" {
+			lname, err = getName(data, fname)
+		} else {
+			lname, err = getGettextName(data, fname)
+			gettext = true
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := a.langAssets[lname]; !dup && lname != defaultLang {
+			a.available = append(a.available, lname)
+		}
+		a.langAssetMap[lname] = Locale(strings.TrimSuffix(fname, ext))
+		a.langAssets[lname] = fname
+	}
+	if _, present := a.langAssetMap[defaultLang]; !present && !gettext {
+		return nil, ErrDefLangAbsent
+	}
+	return &Bundle{assets: a, localizers: make(map[Lingua]*Localizer)}, nil
+}
+
+// Localizer returns the Localizer for lang, loading its translations if it
+// has not been used before. lang is compared to the AA_NativeLangName of the
+// json assets, or the language name of the gettext catalogs; see SetLanguage.
+func (b *Bundle) Localizer(lang Lingua) (*Localizer, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if l, ok := b.localizers[lang]; ok {
+		return l, nil
+	}
+	l, err := b.load(lang)
+	if err != nil {
+		return nil, err
+	}
+	b.localizers[lang] = l
+	return l, nil
+}
+
+// Languages returns the languages of b. The default language is first; the
+// others are unsorted.
+func (b *Bundle) Languages() Linguas { return append(Linguas(nil), b.available...) }
+
+// DefaultLanguage returns the language which phrases are translated from
+func (b *Bundle) DefaultLanguage() Lingua { return b.defaultLanguage }
+
+// Lingua returns the language of b closest to loc, as Locale.Lingua does for
+// the DefaultBundle, or "" if there is none.
+func (b *Bundle) Lingua(loc Locale) Lingua { return b.lingua(loc) }
+
+// Language returns the language l translates to
+func (l *Localizer) Language() Lingua { return l.lang }
+
+// Locale returns the locale of the language l translates to
+func (l *Localizer) Locale() Locale { return l.langAssetMap[l.lang] }
@@ -4,11 +4,29 @@
 // - github.com/jteeuwen/go-bindata (archived, not supported any more)
 // - github.com/go-bindata/go-bindata.
 // In either case, map keys are asset names, such as en-us.json, while values
-// are asset access functions. The asset value (payload) is json from cmd/xtract.
+// are asset access functions. The asset value (payload) is json from cmd/xtract,
+// or a gettext catalog: de.po, or de.mo as compiled by msgfmt. Gettext
+// catalogs are keyed by the phrases in the primary language, so the primary
+// language needs no asset when they are used, and their plural forms are
+// selected by the catalog's Plural-Forms header.
 //
-// This package assumes there are no duplicate strings in the primary language.
-// If two strings are the same in the primary language but differ in another,
-// for example due to context, this package will fail to accurately translate
-// one of those strings; the other translation will always be used. To avoid,
-// ensure the input strings (not the const/var names) are unique.
+// Strings which are the same in the primary language but differ in another,
+// for example "Open" as a verb and as an adjective, must be distinguished by
+// context: call TC rather than T, and cmd/xtract records the context as
+// metadata in the json asset. Without a context, only one translation of
+// such strings can be used.
+//
+// Phrases which depend on a count are translated with TN, which selects the
+// form for the count using the CLDR plural rules of the current language's
+// locale, as given by its asset name. See package plural.
+//
+// T and the other funcs may be called from any number of goroutines, also
+// while SetLanguage changes the language: each call translates entirely to
+// either the old language or the new one.
+//
+// The package-level funcs translate to a single current language. Servers
+// whose users each have their own language should instead get a Localizer for
+// each user's language from a Bundle, such as the DefaultBundle loaded by
+// Setup, and translate with its methods. A Bundle loads the translations of
+// each language once, and its Localizers may be shared between goroutines.
 package xlate
@@ -5,9 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"path"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"unsafe"
+
+	"github.com/mpictor/go-xtract/pkg/catalog"
+	"github.com/mpictor/go-xtract/pkg/plural"
+	"github.com/mpictor/go-xtract/pkg/po"
 )
 
 type (
@@ -27,45 +34,60 @@ type (
 var (
 	// AvailableLanguages is a list of supported languages for the UI. The
 	// first language (english) will be the default. Otherwise unsorted as the
-	// source is keys from a map.
+	// source is keys from a map. It is set by Setup, and must not be modified.
 	AvailableLanguages Linguas
 
-	//the language T()'s input strings are in
-	defaultLanguage Lingua
-
-	//current language for translations
-	curLang Lingua
+	// mu serializes Setup
+	mu sync.Mutex
 
-	// map from language name to locale, which must match asset name - for example
-	// {"English": "en-us",}
-	//      ==>   en-us.json
-	langAssetMap map[Lingua]Locale
+	// the Bundle loaded by Setup
+	defaultBundle atomic.Pointer[Bundle]
 
-	//maps from phrase in primary language to current
-	translations map[string]string
+	// current is the Localizer for the current language. It is replaced by
+	// Setup and SetLanguage, and a Localizer is never modified, so it may be
+	// read from any goroutine while the language changes.
+	current atomic.Pointer[Localizer]
 
 	ErrNotFound       = errors.New("lang asset not found")
 	ErrMultiSetup     = errors.New("setup called multiple times")
 	ErrDefLangAbsent  = errors.New("default language not loaded")
 	ErrLangNameAbsent = errors.New("missing key AA_NativeLangName")
+
+	ErrLangHeaderAbsent = errors.New("missing header X-Native-Language-Name or Language")
 )
 
+// cur returns the Localizer for the current language. Before Setup, it is
+// one for an empty default language, which passes phrases through.
+func cur() *Localizer {
+	if l := current.Load(); l != nil {
+		return l
+	}
+	return &Localizer{assets: &assets{}}
+}
+
+// DefaultBundle returns the Bundle loaded by Setup, which T and the other
+// package-level funcs use, or nil before Setup. Its Localizers translate to
+// any language regardless of the current one, as for the users of a server.
+func DefaultBundle() *Bundle { return defaultBundle.Load() }
+
 func (l Linguas) Len() int           { return len(l) }
 func (l Linguas) Less(i, j int) bool { return strings.Compare(string(l[i]), string(l[j])) < 0 }
 func (l Linguas) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
 
 // Locale converts Lingua to Locale.
 func (l Lingua) Locale() Locale {
-	return Locale(langAssetMap[l])
+	return Locale(cur().langAssetMap[l])
 }
 
 // Lingua converts Locale to Lingua.
-func (l Locale) Lingua() Lingua {
-	fl := l.FuzzyMatch(GetLocales())
+func (l Locale) Lingua() Lingua { return cur().lingua(l) }
+
+func (a *assets) lingua(l Locale) Lingua {
+	fl := l.FuzzyMatch(a.locales())
 	if len(fl) == 0 {
 		return ""
 	}
-	for lin, loc := range langAssetMap {
+	for lin, loc := range a.langAssetMap {
 		if fl == loc {
 			return lin
 		}
@@ -117,63 +139,184 @@ func (l Locale) FuzzyMatch(set []Locale) Locale {
 // this is found, a map is constructed mapping from a phrase in the default
 // language to a phrase in the new language. Subsequent calls to T() use
 // this map to find the correct phrase to return.
-func SetLanguage(lang Lingua) (err error) {
-	if langAssetMap == nil {
+//
+// SetLanguage may be called while T and the other funcs translate in other
+// goroutines: they use either the old language or the new one, never a mix.
+// The translations are loaded by the DefaultBundle, once for each language.
+func SetLanguage(lang Lingua) error {
+	b := defaultBundle.Load()
+	if b == nil {
 		return fmt.Errorf("must call xlate.Setup() first. %s: %w", lang, ErrNotFound)
 	}
 	log.Printf("Setting language to %s", lang)
-	_, ok := langAssetMap[lang]
-	if !ok {
-		return fmt.Errorf("%s: %w", lang, ErrNotFound)
+	l, err := b.Localizer(lang)
+	if err != nil {
+		return err
+	}
+	current.Store(l)
+	return nil
+}
+
+// load loads the translations of lang from a's assets
+func (a *assets) load(lang Lingua) (*Localizer, error) {
+	_, ok := a.langAssetMap[lang]
+	if !ok && lang != a.defaultLanguage {
+		return nil, fmt.Errorf("%s: %w", lang, ErrNotFound)
+	}
+	if lang == a.defaultLanguage {
+		// phrases are in the default language already
+		return &Localizer{assets: a, lang: lang}, nil
+	}
+	if ext := path.Ext(a.langAssets[lang]); ext == ".po" || ext == ".mo" {
+		return a.loadGettext(lang)
 	}
+
 	//load default and target lang, use keys to map def val to target val
-	var defLang, tgtLang map[string]string
-	defLang, err = langMap(defaultLanguage)
+	defLang, err := a.langMap(a.defaultLanguage)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	tgtLang, err = langMap(lang)
+	tgtLang, err := a.langMap(lang)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	//clear out any existing translation
-	for k := range translations {
-		delete(translations, k)
+	// empty translations, such as those added by xtract -sync, are untranslated: T passes the phrase through. So
+	// are stale translations, made from other text than the default language has now.
+	xlations := make(map[string]string)
+	for varname, phrase := range defLang.Messages {
+		if tgt := tgtLang.Messages[varname]; tgt != "" && !tgtLang.Stale(defLang, varname) {
+			xlations[ctxKey(msgContext(defLang, varname), phrase)] = tgt
+		}
 	}
-	translations = make(map[string]string)
 
-	for varname, phrase := range defLang {
-		translations[phrase] = tgtLang[varname]
+	// plural forms are indexed in the order of the CLDR categories the language uses
+	rule, _ := plural.ForLocale(string(a.langAssetMap[lang]))
+	plurals := make(map[string][]string)
+	for varname, forms := range defLang.Plurals {
+		key := ctxKey(msgContext(defLang, varname), forms[plural.One])
+		if tgtLang.Stale(defLang, varname) {
+			continue
+		}
+		tgt, ok := tgtLang.Plurals[varname]
+		if !ok {
+			phrase, ok := tgtLang.Messages[varname]
+			if !ok {
+				continue
+			}
+			// the same phrase for any count
+			tgt = catalog.Plural{plural.Other: phrase}
+		}
+		indexed := make([]string, len(rule.Categories))
+		for i, cat := range rule.Categories {
+			if indexed[i] = tgt[cat]; indexed[i] == "" {
+				indexed[i] = tgt[plural.Other]
+			}
+		}
+		plurals[key] = indexed
 	}
-	curLang = lang
-	return nil
+	return &Localizer{assets: a, lang: lang, translations: xlations, pluralTranslations: plurals,
+		pluralIndex: func(n int) int {
+			cat := rule.Form(n)
+			for i, c := range rule.Categories {
+				if c == cat {
+					return i
+				}
+			}
+			return 0
+		}}, nil
+}
+
+// loadGettext loads the translations for lang from a .po or .mo asset. Fuzzy and untranslated messages are
+// ignored, as by msgfmt.
+func (a *assets) loadGettext(lang Lingua) (*Localizer, error) {
+	f, err := a.gettextFile(a.langAssets[lang])
+	if err != nil {
+		return nil, err
+	}
+	forms, err := f.Header.PluralForms()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", a.langAssets[lang], err)
+	}
+	xlations := make(map[string]string)
+	plurals := make(map[string][]string)
+	for _, m := range f.Messages {
+		if m.Obsolete || m.Fuzzy() || len(m.Str) == 0 {
+			continue
+		}
+		if m.IsPlural() {
+			plurals[m.Key()] = m.Str
+		} else if m.Str[0] != "" {
+			xlations[m.Key()] = m.Str[0]
+		}
+	}
+	return &Localizer{assets: a, lang: lang, translations: xlations, pluralTranslations: plurals,
+		pluralIndex: forms.Index}, nil
+}
+
+// gettextFile loads and parses a .po or .mo asset
+func (a *assets) gettextFile(assetName string) (*po.File, error) {
+	datafn, ok := a.bindata[assetName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, assetName)
+	}
+	data, err := datafn()
+	if err != nil {
+		return nil, err
+	}
+	return parseGettext(data, assetName)
+}
+
+// parseGettext parses data, a .po or .mo asset, by its name
+func parseGettext(data []byte, assetName string) (f *po.File, err error) {
+	if path.Ext(assetName) == ".mo" {
+		f, err = po.ReadMO(data)
+	} else {
+		f, err = po.ReadBytes(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", assetName, err)
+	}
+	return f, nil
+}
+
+// msgContext returns the context of the message with the given key
+func msgContext(c *catalog.Catalog, key string) string {
+	if meta := c.Meta[key]; meta != nil {
+		return meta.Context
+	}
+	return ""
 }
 
 //loads lang asset; asset maps from var name to phrase
-func langMap(lang Lingua) (m map[string]string, err error) {
+func (a *assets) langMap(lang Lingua) (c *catalog.Catalog, err error) {
 	var data []byte
-	assetName := string(langAssetMap[lang]) + ".json"
-	datafn, ok := bindata[assetName]
+	assetName := string(a.langAssetMap[lang]) + ".json"
+	datafn, ok := a.bindata[assetName]
 	if !ok {
 		return nil, fmt.Errorf("%w: %s", ErrNotFound, lang)
 	}
 	data, err = datafn()
-	if err == nil {
-		err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
 	}
-	return
+	return catalog.Read(data)
 }
 
 // GetLanguage returns the current lingua.
-func GetLanguage() Lingua { return curLang }
+func GetLanguage() Lingua { return cur().lang }
 
 // GetLocale returns the current locale.
-func GetLocale() Locale { return langAssetMap[curLang] }
+func GetLocale() Locale {
+	l := cur()
+	return l.langAssetMap[l.lang]
+}
 
 // GetLocales returns all available locales.
-func GetLocales() []Locale {
+func GetLocales() []Locale { return cur().locales() }
+
+func (a *assets) locales() []Locale {
 	var locs []Locale
-	for _, l := range langAssetMap {
+	for _, l := range a.langAssetMap {
 		locs = append(locs, l)
 	}
 	return locs
@@ -184,46 +327,29 @@ func GetLocales() []Locale {
 // AdoptBindata() wrapping function.
 type Bindata map[string]func() ([]byte, error)
 
-var bindata Bindata
-
 var loaded = false
 
-// Setup adds languages to AvailableLanguages based on assets found. This
-// function must be called exactly once, and before any other funcs in the
-// package.
+// Setup adds languages to AvailableLanguages based on assets found, as
+// NewBundle does for the DefaultBundle. This function must be called exactly
+// once, and before any other funcs in the package.
 func Setup(defaultLang Lingua, bdata Bindata) error {
+	mu.Lock()
+	defer mu.Unlock()
 	if loaded {
 		return ErrMultiSetup
 	}
-	defaultLanguage = defaultLang
-	curLang = defaultLang
-	bindata = bdata
-	langAssetMap = make(map[Lingua]Locale)
-	AvailableLanguages = []Lingua{defaultLanguage}
-	for fname, loader := range bindata {
-		if !strings.HasSuffix(fname, ".json") {
-			continue
-		}
-		var err error
-		var data []byte
-		var lname Lingua
-		//if name == "yo-da.json" {continue}
-		if data, err = loader(); err != nil {
-			return err
-		}
-		if lname, err = getName(data, fname); err != nil {
-			return err
-		}
-		if lname != defaultLanguage {
-			AvailableLanguages = append(AvailableLanguages, lname)
-		}
-		langAssetMap[lname] = Locale(strings.TrimSuffix(fname, ".json"))
-	}
-	if _, present := langAssetMap[defaultLanguage]; !present {
-		langAssetMap = nil
+	b, err := NewBundle(defaultLang, bdata)
+	if err != nil {
 		AvailableLanguages = nil
-		return ErrDefLangAbsent
+		return err
 	}
+	l, err := b.Localizer(defaultLang)
+	if err != nil {
+		return err
+	}
+	AvailableLanguages = b.Languages()
+	defaultBundle.Store(b)
+	current.Store(l)
 	loaded = true
 	return nil
 }
@@ -328,3 +454,19 @@ func getName(jdata []byte, fname string) (Lingua, error) {
 	}
 	return Lingua(l.AA_NativeLangName), nil
 }
+
+// looks for the language name in the header of a gettext catalog
+func getGettextName(data []byte, fname string) (Lingua, error) {
+	f, err := parseGettext(data, fname)
+	if err != nil {
+		return "", err
+	}
+	name := f.Header.Get("X-Native-Language-Name")
+	if name == "" {
+		name = f.Header.Get("Language")
+	}
+	if name == "" {
+		return "", fmt.Errorf("%s: %w", fname, ErrLangHeaderAbsent)
+	}
+	return Lingua(name), nil
+}
@@ -4,5 +4,7 @@ package xlate
 
 // TestingClearSetupCheck clears loaded bool so that we can load multiple datasets in a testing binary
 func TestingClearSetupCheck() {
+	mu.Lock()
+	defer mu.Unlock()
 	loaded = false
 }
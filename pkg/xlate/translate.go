@@ -3,31 +3,135 @@ package xlate
 import (
 	"fmt"
 	"log"
+
+	"github.com/mpictor/go-xtract/pkg/plural"
 )
 
 // T looks up a translation. Input is in the primary language, while output is
 // in the current language. If no match is found, a warning is logged and the
 // string passes through as-is.
-func T(in string) string {
-	out, err := TErr(in)
+func T(in string) string { return cur().T(in) }
+
+//Like T, but returns an error rather than logging.
+func TErr(in string) (string, error) { return cur().TErr(in) }
+
+// TC looks up a translation of a string which occurs in the given context.
+// Identical strings in the primary language may be translated differently in
+// different contexts, such as "Open" as a verb and as an adjective. The
+// context is matched against the "context" metadata in the json asset. An
+// empty context is the same as calling T.
+func TC(ctx, in string) string { return cur().TC(ctx, in) }
+
+//Like TC, but returns an error rather than logging.
+func TCErr(ctx, in string) (string, error) { return cur().TCErr(ctx, in) }
+
+// TN looks up a translation of a phrase which depends on a count, such as
+// "%d file deleted". singular and plural are the forms in the primary
+// language; the output is the form for n in the current language, according
+// to the CLDR plural rules for its locale. If no match is found, a warning is
+// logged and singular or plural is used as appropriate for n.
+func TN(singular, plural string, n int) string { return cur().TN(singular, plural, n) }
+
+//Like TN, but returns an error rather than logging.
+func TNErr(singular, plural string, n int) (string, error) { return cur().TNErr(singular, plural, n) }
+
+// TCN is like TN, for a phrase which occurs in the given context. See TC.
+func TCN(ctx, singular, plural string, n int) string { return cur().TCN(ctx, singular, plural, n) }
+
+//Like TCN, but returns an error rather than logging.
+func TCNErr(ctx, singular, plural string, n int) (string, error) {
+	return cur().TCNErr(ctx, singular, plural, n)
+}
+
+// T is like the package-level T, translating to l's language rather than the
+// current one.
+func (l *Localizer) T(in string) string {
+	out, err := l.TErr(in)
 	if err != nil {
 		log.Print(err)
 	}
 	return out
 }
 
-//Like T, but returns an error rather than logging.
-func TErr(in string) (string, error) {
-	if curLang == defaultLanguage {
+// TErr is like T, but returns an error rather than logging.
+func (l *Localizer) TErr(in string) (string, error) {
+	return l.TCErr("", in)
+}
+
+// TC is like the package-level TC, translating to l's language.
+func (l *Localizer) TC(ctx, in string) string {
+	out, err := l.TCErr(ctx, in)
+	if err != nil {
+		log.Print(err)
+	}
+	return out
+}
+
+// TCErr is like TC, but returns an error rather than logging.
+func (l *Localizer) TCErr(ctx, in string) (string, error) {
+	if l.lang == l.defaultLanguage {
 		return in, nil
 	}
-	if translations == nil {
+	if l.translations == nil {
 		return in, fmt.Errorf("T(%s) called before xlate.SetLanguage - translation impossible", in)
 	}
-	out, ok := translations[in]
+	out, ok := l.translations[ctxKey(ctx, in)]
 	if ok {
 		return out, nil
 	}
 	//shouldn't get here, but just in case...
-	return in, fmt.Errorf("T(%q): missing translation to %s", in, curLang)
+	if ctx != "" {
+		return in, fmt.Errorf("TC(%q, %q): missing translation to %s", ctx, in, l.lang)
+	}
+	return in, fmt.Errorf("T(%q): missing translation to %s", in, l.lang)
+}
+
+// ctxKey identifies a phrase by its context and text, as in gettext's .mo files
+func ctxKey(ctx, in string) string {
+	if ctx == "" {
+		return in
+	}
+	return ctx + "\x04" + in
+}
+
+// TN is like the package-level TN, translating to l's language.
+func (l *Localizer) TN(singular, plural string, n int) string {
+	return l.TCN("", singular, plural, n)
+}
+
+// TNErr is like TN, but returns an error rather than logging.
+func (l *Localizer) TNErr(singular, plural string, n int) (string, error) {
+	return l.TCNErr("", singular, plural, n)
+}
+
+// TCN is like the package-level TCN, translating to l's language.
+func (l *Localizer) TCN(ctx, singular, plural string, n int) string {
+	out, err := l.TCNErr(ctx, singular, plural, n)
+	if err != nil {
+		log.Print(err)
+	}
+	return out
+}
+
+// TCNErr is like TCN, but returns an error rather than logging.
+func (l *Localizer) TCNErr(ctx, singular, pluralForm string, n int) (string, error) {
+	in := singular
+	if plural.Form(string(l.langAssetMap[l.defaultLanguage]), n) != plural.One {
+		in = pluralForm
+	}
+	if l.lang == l.defaultLanguage {
+		return in, nil
+	}
+	if l.pluralTranslations == nil {
+		return in, fmt.Errorf("TN(%s) called before xlate.SetLanguage - translation impossible", singular)
+	}
+	forms, ok := l.pluralTranslations[ctxKey(ctx, singular)]
+	if !ok {
+		return in, fmt.Errorf("TN(%q, %q): missing translation to %s", ctx, singular, l.lang)
+	}
+	i := l.pluralIndex(n)
+	if i < len(forms) && forms[i] != "" {
+		return forms[i], nil
+	}
+	return in, fmt.Errorf("TN(%q, %q): missing plural form %d in %s", ctx, singular, i, l.lang)
 }
@@ -1,8 +1,12 @@
 package xlate
 
 import (
+	"bytes"
+	"errors"
+	"sync"
 	"testing"
 
+	"github.com/mpictor/go-xtract/pkg/po"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,6 +55,172 @@ func TestTranslations(t *testing.T) {
 	require.Equal(t, unxlated, out, "no translation - must pass through verbatim")
 }
 
+func TestMetadataIgnored(t *testing.T) {
+	bd := Bindata{
+		"te-st.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"test","@Str":{"references":["a.go:1"]},"Str":"` + Str + `"}`), nil
+		},
+		"ot-hr.json": func() ([]byte, error) { return []byte(otherjson), nil },
+	}
+	loaded = false
+	err := Setup("test", bd)
+	require.NoError(t, err, "bindata is valid")
+
+	err = SetLanguage("other")
+	require.NoError(t, err, "set lang to valid choice")
+	out := T(Str)
+	require.Equal(t, StrOther, out, "translation available - must translate")
+}
+
+func TestUntranslated(t *testing.T) {
+	bd := Bindata{
+		"te-st.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"test","Quit":"Quit","Stale":"New","Str":"` + Str + `"}`), nil
+		},
+		"ot-hr.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"other","Quit":"","Str":"` + StrOther + `","@@obsolete":{"Exit":"x"},
+				"Stale":"Old","@Stale":{"source":"0123456789abcdef"}}`), nil
+		},
+	}
+	loaded = false
+	err := Setup("test", bd)
+	require.NoError(t, err, "bindata is valid")
+
+	err = SetLanguage("other")
+	require.NoError(t, err, "set lang to valid choice")
+	out, err := TErr("Quit")
+	assert.Error(t, err, "empty translation - must be missing")
+	assert.Equal(t, "Quit", out, "empty translation - must pass through verbatim")
+	assert.Equal(t, StrOther, T(Str))
+	assert.Equal(t, "New", T("New"), "stale translation - must pass through verbatim")
+}
+
+func TestContext(t *testing.T) {
+	bd := Bindata{
+		"te-st.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"test","OpenVerb":"Open","@OpenVerb":{"context":"verb"},` +
+				`"OpenAdj":"Open","@OpenAdj":{"context":"adjective"},"Close":"Close"}`), nil
+		},
+		"ot-hr.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"other","OpenVerb":"Öffnen","OpenAdj":"Offen","Close":"Schließen"}`), nil
+		},
+	}
+	loaded = false
+	err := Setup("test", bd)
+	require.NoError(t, err, "bindata is valid")
+	assert.Equal(t, "Open", TC("verb", "Open"), "same language - must match")
+
+	err = SetLanguage("other")
+	require.NoError(t, err, "set lang to valid choice")
+	assert.Equal(t, "Öffnen", TC("verb", "Open"))
+	assert.Equal(t, "Offen", TC("adjective", "Open"))
+	assert.Equal(t, "Schließen", TC("", "Close"), "empty context - same as T")
+	assert.Equal(t, "Schließen", T("Close"))
+
+	out, err := TCErr("noun", "Open")
+	assert.Error(t, err, "context must match")
+	assert.Equal(t, "Open", out, "no translation - must pass through verbatim")
+	_, err = TErr("Open")
+	assert.Error(t, err, "message with context requires it")
+}
+
+func TestPlural(t *testing.T) {
+	bd := Bindata{
+		"en.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"English",` +
+				`"Deleted":{"one":"%d file deleted","other":"%d files deleted"},` +
+				`"Found":{"one":"%d match","other":"%d matches"},"@Found":{"context":"search"}}`), nil
+		},
+		"pl.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"polski",` +
+				`"Deleted":{"one":"Usunięto %d plik","few":"Usunięto %d pliki","many":"Usunięto %d plików"},` +
+				`"Found":"Wyniki: %d"}`), nil
+		},
+	}
+	loaded = false
+	err := Setup("English", bd)
+	require.NoError(t, err, "bindata is valid")
+	assert.Equal(t, "%d file deleted", TN("%d file deleted", "%d files deleted", 1))
+	assert.Equal(t, "%d files deleted", TN("%d file deleted", "%d files deleted", 0))
+
+	err = SetLanguage("polski")
+	require.NoError(t, err, "set lang to valid choice")
+	for n, want := range map[int]string{
+		1:  "Usunięto %d plik",
+		2:  "Usunięto %d pliki",
+		5:  "Usunięto %d plików",
+		22: "Usunięto %d pliki",
+	} {
+		assert.Equal(t, want, TN("%d file deleted", "%d files deleted", n), "%d", n)
+	}
+	assert.Equal(t, "Wyniki: %d", TCN("search", "%d match", "%d matches", 3), "single form for all counts")
+
+	out, err := TNErr("%d match", "%d matches", 3)
+	assert.Error(t, err, "context must match")
+	assert.Equal(t, "%d matches", out, "no translation - must pass through")
+}
+
+const dePO = `msgid ""
+msgstr ""
+"Language: de\n"
+"X-Native-Language-Name: Deutsch\n"
+
+msgid "Hello"
+msgstr "Hallo"
+
+msgctxt "verb"
+msgid "Open"
+msgstr "Öffnen"
+
+#, fuzzy
+msgid "Close"
+msgstr "Schließen"
+`
+
+const plPO = `msgid ""
+msgstr ""
+"Language: pl\n"
+"Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "%d file deleted"
+msgid_plural "%d files deleted"
+msgstr[0] "Usunięto %d plik"
+msgstr[1] "Usunięto %d pliki"
+msgstr[2] "Usunięto %d plików"
+`
+
+func TestGettext(t *testing.T) {
+	pl, err := po.ReadBytes([]byte(plPO))
+	require.NoError(t, err)
+	var mo bytes.Buffer
+	require.NoError(t, pl.WriteMO(&mo))
+
+	bd := Bindata{
+		"de.po": func() ([]byte, error) { return []byte(dePO), nil },
+		"pl.mo": func() ([]byte, error) { return mo.Bytes(), nil },
+	}
+	loaded = false
+	err = Setup("English", bd)
+	require.NoError(t, err, "default language needs no asset when using gettext")
+	assert.ElementsMatch(t, Linguas{"English", "Deutsch", "pl"}, AvailableLanguages)
+	assert.Equal(t, "Hello", T("Hello"))
+
+	require.NoError(t, SetLanguage("Deutsch"))
+	assert.Equal(t, "Hallo", T("Hello"))
+	assert.Equal(t, "Öffnen", TC("verb", "Open"))
+	out, err := TErr("Close")
+	assert.Error(t, err, "fuzzy translations are not used")
+	assert.Equal(t, "Close", out)
+
+	require.NoError(t, SetLanguage("pl"))
+	for n, want := range map[int]string{1: "Usunięto %d plik", 3: "Usunięto %d pliki", 5: "Usunięto %d plików"} {
+		assert.Equal(t, want, TN("%d file deleted", "%d files deleted", n), "%d", n)
+	}
+
+	require.NoError(t, SetLanguage("English"))
+	assert.Equal(t, "%d files deleted", TN("%d file deleted", "%d files deleted", 2))
+}
+
 func TestMissingLang(t *testing.T) {
 	bd := Bindata{
 		"te-st.json": func() ([]byte, error) { return []byte(tsjson), nil },
@@ -66,6 +236,133 @@ func TestMissingLang(t *testing.T) {
 	require.Equal(t, out, Str, "no translation - must pass through verbatim")
 }
 
+// TestConcurrent translates in several goroutines while the language changes. Run it with -race.
+func TestConcurrent(t *testing.T) {
+	bd := Bindata{
+		"en.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"English","Str":"` + Str + `",` +
+				`"Deleted":{"one":"%d file deleted","other":"%d files deleted"}}`), nil
+		},
+		"pl.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"polski","Str":"` + StrOther + `",` +
+				`"Deleted":{"one":"Usunięto %d plik","few":"Usunięto %d pliki","many":"Usunięto %d plików"}}`), nil
+		},
+	}
+	loaded = false
+	require.NoError(t, Setup("English", bd))
+
+	want := map[Lingua][2]string{
+		"English": {Str, "%d files deleted"},
+		"polski":  {StrOther, "Usunięto %d plików"},
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// one snapshot gives the translations of one language
+				l := cur()
+				out, err := l.TErr(Str)
+				assert.NoError(t, err)
+				assert.Equal(t, want[l.lang][0], out, l.lang)
+				out, err = l.TNErr("%d file deleted", "%d files deleted", 5)
+				assert.NoError(t, err)
+				assert.Equal(t, want[l.lang][1], out, l.lang)
+
+				assert.Contains(t, []string{Str, StrOther}, T(Str))
+				assert.Contains(t, []string{"%d files deleted", "Usunięto %d plików"},
+					TN("%d file deleted", "%d files deleted", 5))
+				assert.Contains(t, []Locale{"en", "pl"}, GetLocale())
+				assert.Contains(t, want, GetLanguage())
+
+				pl, err := DefaultBundle().Localizer("polski")
+				assert.NoError(t, err)
+				assert.Equal(t, StrOther, pl.T(Str))
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		require.NoError(t, SetLanguage("polski"))
+		require.NoError(t, SetLanguage("English"))
+	}
+	close(done)
+	wg.Wait()
+	assert.Equal(t, Str, T(Str))
+}
+
+func TestBundle(t *testing.T) {
+	bd := Bindata{
+		"en-us.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"English","Str":"` + Str + `","OpenVerb":"Open","@OpenVerb":{"context":"verb"},` +
+				`"Deleted":{"one":"%d file deleted","other":"%d files deleted"}}`), nil
+		},
+		"pl.json": func() ([]byte, error) {
+			return []byte(`{"AA_NativeLangName":"polski","Str":"` + StrOther + `","OpenVerb":"Otwórz",` +
+				`"Deleted":{"one":"Usunięto %d plik","few":"Usunięto %d pliki","many":"Usunięto %d plików"}}`), nil
+		},
+	}
+	reads := 0
+	bd["de.po"] = func() ([]byte, error) {
+		reads++
+		return []byte(dePO), nil
+	}
+	_, err := NewBundle("Klingon", Bindata{"pl.json": bd["pl.json"]})
+	assert.True(t, errors.Is(err, ErrDefLangAbsent), err)
+
+	b, err := NewBundle("English", bd)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reads, "each asset is read once")
+	assert.Equal(t, Lingua("English"), b.DefaultLanguage())
+	assert.Equal(t, Lingua("English"), b.Languages()[0])
+	assert.ElementsMatch(t, Linguas{"English", "polski", "Deutsch"}, b.Languages())
+	assert.Equal(t, Lingua("polski"), b.Lingua("pl-PL"))
+	assert.Equal(t, Lingua(""), b.Lingua("fr"))
+
+	_, err = b.Localizer("Klingon")
+	assert.True(t, errors.Is(err, ErrNotFound), err)
+
+	en, err := b.Localizer("English")
+	require.NoError(t, err)
+	pl, err := b.Localizer("polski")
+	require.NoError(t, err)
+	de, err := b.Localizer("Deutsch")
+	require.NoError(t, err)
+	again, err := b.Localizer("polski")
+	require.NoError(t, err)
+	assert.Same(t, pl, again, "translations are loaded once")
+
+	assert.Equal(t, Lingua("polski"), pl.Language())
+	assert.Equal(t, Locale("pl"), pl.Locale())
+	assert.Equal(t, Locale("en-us"), en.Locale())
+	assert.Equal(t, Str, en.T(Str))
+	assert.Equal(t, StrOther, pl.T(Str))
+	assert.Equal(t, "Otwórz", pl.TC("verb", "Open"))
+	assert.Equal(t, "Öffnen", de.TC("verb", "Open"))
+	assert.Equal(t, "%d files deleted", en.TN("%d file deleted", "%d files deleted", 5))
+	assert.Equal(t, "Usunięto %d pliki", pl.TN("%d file deleted", "%d files deleted", 3))
+	out, err := de.TErr("Close")
+	assert.Error(t, err, "fuzzy translations are not used")
+	assert.Equal(t, "Close", out)
+
+	// a Bundle is independent of the package-level current language
+	loaded = false
+	require.NoError(t, Setup("English", bd))
+	require.NoError(t, SetLanguage("Deutsch"))
+	assert.Equal(t, StrOther, pl.T(Str))
+	assert.Equal(t, "Hallo", T("Hello"))
+	assert.False(t, b == DefaultBundle())
+	l, err := DefaultBundle().Localizer("Deutsch")
+	require.NoError(t, err)
+	assert.Same(t, l, cur(), "SetLanguage uses the DefaultBundle")
+}
+
 func TestAdoptBindata(t *testing.T) {
 	type someAsset struct {
 		d []byte
@@ -0,0 +1,169 @@
+package xliff
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+type xliff12 struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string   `xml:"version,attr"`
+	Files   []file12 `xml:"file"`
+}
+
+type file12 struct {
+	Original string      `xml:"original,attr"`
+	Source   string      `xml:"source-language,attr"`
+	Target   string      `xml:"target-language,attr,omitempty"`
+	Datatype string      `xml:"datatype,attr"`
+	Units    []transUnit `xml:"body>trans-unit"`
+}
+
+type transUnit struct {
+	ID       string         `xml:"id,attr"`
+	Approved string         `xml:"approved,attr,omitempty"`
+	Source   string         `xml:"source"`
+	Target   *target12      `xml:"target"`
+	Groups   []contextGroup `xml:"context-group"`
+	Notes    []note12       `xml:"note"`
+}
+
+type target12 struct {
+	State string `xml:"state,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+type contextGroup struct {
+	Purpose  string    `xml:"purpose,attr"`
+	Contexts []context `xml:"context"`
+}
+
+type context struct {
+	Type string `xml:"context-type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type note12 struct {
+	From string `xml:"from,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+// note authors distinguishing the message context from comments
+const (
+	fromContext   = "context"
+	fromDeveloper = "developer"
+)
+
+func (d *Document) to12() *xliff12 {
+	f := file12{Original: d.Original, Source: d.SourceLang, Target: d.TargetLang, Datatype: "plaintext"}
+	for _, u := range d.Units {
+		tu := transUnit{ID: u.ID, Source: u.Source}
+		if u.Target != "" || u.State != "" {
+			tu.Target = &target12{State: state12(u.State), Text: u.Target}
+		}
+		if u.State == StateFinal {
+			tu.Approved = "yes"
+		}
+		for _, loc := range u.Locations {
+			file, line := loc, ""
+			if i := strings.LastIndex(loc, ":"); i >= 0 {
+				file, line = loc[:i], loc[i+1:]
+			}
+			g := contextGroup{Purpose: "location", Contexts: []context{{"sourcefile", file}}}
+			if line != "" {
+				g.Contexts = append(g.Contexts, context{"linenumber", line})
+			}
+			tu.Groups = append(tu.Groups, g)
+		}
+		if u.Context != "" {
+			tu.Notes = append(tu.Notes, note12{fromContext, u.Context})
+		}
+		for _, n := range u.Notes {
+			tu.Notes = append(tu.Notes, note12{fromDeveloper, n})
+		}
+		f.Units = append(f.Units, tu)
+	}
+	return &xliff12{Version: Version12, Files: []file12{f}}
+}
+
+func read12(data []byte) (*Document, error) {
+	var x xliff12
+	if err := xml.Unmarshal(data, &x); err != nil {
+		return nil, err
+	}
+	d := &Document{Version: Version12}
+	for i, f := range x.Files {
+		if i == 0 {
+			d.Original, d.SourceLang, d.TargetLang = f.Original, f.Source, f.Target
+		}
+		for _, tu := range f.Units {
+			u := Unit{ID: tu.ID, Source: tu.Source}
+			if tu.Target != nil {
+				u.Target = tu.Target.Text
+				u.State = parseState12(tu.Target.State, u.Target != "")
+			}
+			if tu.Approved == "yes" {
+				u.State = StateFinal
+			}
+			for _, g := range tu.Groups {
+				if g.Purpose != "location" {
+					continue
+				}
+				var file, line string
+				for _, c := range g.Contexts {
+					switch c.Type {
+					case "sourcefile":
+						file = c.Text
+					case "linenumber":
+						line = c.Text
+					}
+				}
+				if line != "" {
+					file += ":" + line
+				}
+				u.Locations = append(u.Locations, file)
+			}
+			for _, n := range tu.Notes {
+				if n.From == fromContext {
+					u.Context = n.Text
+				} else {
+					u.Notes = append(u.Notes, n.Text)
+				}
+			}
+			d.Units = append(d.Units, u)
+		}
+	}
+	return d, nil
+}
+
+// state12 returns the XLIFF 1.2 target state nearest s
+func state12(s State) string {
+	switch s {
+	case StateInitial:
+		return "new"
+	case StateTranslated:
+		return "translated"
+	case StateReviewed:
+		return "signed-off"
+	case StateFinal:
+		return "final"
+	}
+	return ""
+}
+
+// parseState12 maps an XLIFF 1.2 target state to the nearest State. Targets without a state are taken as
+// translated if not empty.
+func parseState12(s string, hasTarget bool) State {
+	switch {
+	case s == "final":
+		return StateFinal
+	case s == "signed-off":
+		return StateReviewed
+	case s == "translated", strings.HasPrefix(s, "needs-review"):
+		return StateTranslated
+	case s == "" && hasTarget:
+		return StateTranslated
+	}
+	// new, needs-translation, needs-adaptation, needs-l10n, or x- states
+	return StateInitial
+}
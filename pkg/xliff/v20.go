@@ -0,0 +1,148 @@
+package xliff
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type xliff20 struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string   `xml:"version,attr"`
+	Source  string   `xml:"srcLang,attr"`
+	Target  string   `xml:"trgLang,attr,omitempty"`
+	Files   []file20 `xml:"file"`
+}
+
+type file20 struct {
+	ID       string   `xml:"id,attr"`
+	Original string   `xml:"original,attr,omitempty"`
+	Units    []unit20 `xml:"unit"`
+}
+
+type unit20 struct {
+	ID       string      `xml:"id,attr"`
+	Name     string      `xml:"name,attr,omitempty"`
+	Notes    []note20    `xml:"notes>note"`
+	Segments []segment20 `xml:"segment"`
+}
+
+type note20 struct {
+	Category string `xml:"category,attr,omitempty"`
+	Text     string `xml:",chardata"`
+}
+
+type segment20 struct {
+	State  string  `xml:"state,attr,omitempty"`
+	Source string  `xml:"source"`
+	Target *string `xml:"target"`
+}
+
+// note categories for the message context and locations; other notes are comments
+const (
+	categoryContext  = "context"
+	categoryLocation = "location"
+)
+
+// to20 converts to XLIFF 2.0. Unit ids must be NMTOKENs there, so Unit.ID is written as the unit's name, and the
+// id derived from it.
+func (d *Document) to20() *xliff20 {
+	f := file20{ID: "f1", Original: d.Original}
+	ids := make(map[string]bool)
+	for _, u := range d.Units {
+		id := nmtoken(u.ID)
+		for i := 2; ids[id]; i++ {
+			id = nmtoken(u.ID) + "_" + strconv.Itoa(i)
+		}
+		ids[id] = true
+		xu := unit20{ID: id}
+		if id != u.ID {
+			xu.Name = u.ID
+		}
+		if u.Context != "" {
+			xu.Notes = append(xu.Notes, note20{categoryContext, u.Context})
+		}
+		for _, loc := range u.Locations {
+			xu.Notes = append(xu.Notes, note20{categoryLocation, loc})
+		}
+		for _, n := range u.Notes {
+			xu.Notes = append(xu.Notes, note20{"", n})
+		}
+		seg := segment20{State: string(u.State), Source: u.Source}
+		if u.Target != "" {
+			t := u.Target
+			seg.Target = &t
+		}
+		xu.Segments = []segment20{seg}
+		f.Units = append(f.Units, xu)
+	}
+	return &xliff20{Version: Version20, Source: d.SourceLang, Target: d.TargetLang, Files: []file20{f}}
+}
+
+func read20(data []byte) (*Document, error) {
+	var x xliff20
+	if err := xml.Unmarshal(data, &x); err != nil {
+		return nil, err
+	}
+	d := &Document{Version: Version20, SourceLang: x.Source, TargetLang: x.Target}
+	for i, f := range x.Files {
+		if i == 0 {
+			d.Original = f.Original
+		}
+		for _, xu := range f.Units {
+			u := Unit{ID: xu.ID}
+			if xu.Name != "" {
+				u.ID = xu.Name
+			}
+			for _, n := range xu.Notes {
+				switch n.Category {
+				case categoryContext:
+					u.Context = n.Text
+				case categoryLocation:
+					u.Locations = append(u.Locations, n.Text)
+				default:
+					u.Notes = append(u.Notes, n.Text)
+				}
+			}
+			// segments of a unit are joined; the least advanced state is the unit's
+			for i, seg := range xu.Segments {
+				u.Source += seg.Source
+				state := State(seg.State)
+				if state == "" {
+					state = StateInitial
+				}
+				if seg.Target != nil {
+					u.Target += *seg.Target
+				}
+				if i == 0 || stateOrder(state) < stateOrder(u.State) {
+					u.State = state
+				}
+			}
+			d.Units = append(d.Units, u)
+		}
+	}
+	return d, nil
+}
+
+func stateOrder(s State) int {
+	for i, st := range []State{StateInitial, StateTranslated, StateReviewed, StateFinal} {
+		if s == st {
+			return i
+		}
+	}
+	return 0
+}
+
+// nmtoken replaces characters not allowed in an XML NMTOKEN with '_'
+func nmtoken(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,98 @@
+// Package xliff reads and writes XLIFF 1.2 and 2.0 documents, for exchanging
+// messages with translation vendors and tools.
+//
+// Both versions are read into, and written from, the same Document. Only the
+// parts of XLIFF needed to carry plain text messages are supported: inline
+// markup in sources and targets is not.
+//
+// See http://docs.oasis-open.org/xliff/v1.2/os/xliff-core.html and
+// http://docs.oasis-open.org/xliff/xliff-core/v2.0/xliff-core-v2.0.html
+package xliff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// Supported XLIFF versions
+const (
+	Version12 = "1.2"
+	Version20 = "2.0"
+)
+
+// Document is an XLIFF document holding the messages of one file, in one pair of languages
+type Document struct {
+	Version    string // Version12 or Version20
+	SourceLang string // such as "en-US"
+	TargetLang string // empty in documents to be translated into any language
+	Original   string // name of the file the messages come from
+	Units      []Unit
+}
+
+// Unit is a message to be translated: a trans-unit in XLIFF 1.2, or a unit of a single segment in XLIFF 2.0
+type Unit struct {
+	ID        string
+	Source    string
+	Target    string
+	State     State
+	Context   string   // disambiguates identical sources
+	Notes     []string // comments for translators
+	Locations []string // places the message is used, as file:line
+}
+
+// State is the state of a translation, as defined by XLIFF 2.0. XLIFF 1.2 states are mapped to the nearest.
+type State string
+
+// Translation states, in order of progress
+const (
+	StateInitial    State = "initial"
+	StateTranslated State = "translated"
+	StateReviewed   State = "reviewed"
+	StateFinal      State = "final"
+)
+
+// Read parses an XLIFF 1.2 or 2.0 document
+func Read(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var probe struct {
+		Version string `xml:"version,attr"`
+	}
+	if err := xml.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	switch probe.Version {
+	case Version12:
+		return read12(data)
+	case Version20:
+		return read20(data)
+	}
+	return nil, fmt.Errorf("unsupported XLIFF version %q", probe.Version)
+}
+
+// Write writes the document in the XLIFF version given by its Version field
+func (d *Document) Write(w io.Writer) error {
+	var v interface{}
+	switch d.Version {
+	case Version12:
+		v = d.to12()
+	case Version20:
+		v = d.to20()
+	default:
+		return fmt.Errorf("unsupported XLIFF version %q", d.Version)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
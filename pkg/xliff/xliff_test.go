@@ -0,0 +1,72 @@
+package xliff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var doc = Document{
+	SourceLang: "en",
+	TargetLang: "de",
+	Original:   "messages",
+	Units: []Unit{
+		{ID: "HelloWorld", Source: "Hello, <World>!", State: StateInitial, Notes: []string{"greeting"}, Locations: []string{"main.go:12"}},
+		{ID: "verb_Open", Source: "Open", Context: "verb", Target: "Öffnen", State: StateTranslated},
+		{ID: "Deleted[one]", Source: "%d file deleted", Target: "%d Datei gelöscht", State: StateFinal},
+		{ID: "Deleted/other", Source: "%d files deleted", State: StateInitial},
+	},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, version := range []string{Version12, Version20} {
+		d := doc
+		d.Version = version
+		var buf bytes.Buffer
+		require.NoError(t, d.Write(&buf), version)
+		got, err := Read(&buf)
+		require.NoError(t, err, version)
+		assert.Equal(t, &d, got, version)
+	}
+}
+
+func TestWrite20(t *testing.T) {
+	d := doc
+	d.Version = Version20
+	var buf bytes.Buffer
+	require.NoError(t, d.Write(&buf))
+	assert.Contains(t, buf.String(), `<unit id="Deleted_one_" name="Deleted[one]">`, "ids must be NMTOKENs")
+	assert.Contains(t, buf.String(), `<segment state="final">`)
+
+	d.Version = "3.0"
+	assert.Error(t, d.Write(&buf))
+}
+
+func TestRead12States(t *testing.T) {
+	const x = `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="messages" source-language="en" target-language="fr" datatype="plaintext">
+    <body>
+      <trans-unit id="a"><source>a</source><target>A</target></trans-unit>
+      <trans-unit id="b"><source>b</source><target state="needs-review-translation">B</target></trans-unit>
+      <trans-unit id="c"><source>c</source><target state="needs-translation"></target></trans-unit>
+      <trans-unit id="d" approved="yes"><source>d</source><target>D</target></trans-unit>
+      <trans-unit id="e"><source>e</source></trans-unit>
+    </body>
+  </file>
+</xliff>`
+	d, err := Read(strings.NewReader(x))
+	require.NoError(t, err)
+	assert.Equal(t, "fr", d.TargetLang)
+	var states []State
+	for _, u := range d.Units {
+		states = append(states, u.State)
+	}
+	assert.Equal(t, []State{StateTranslated, StateTranslated, StateInitial, StateFinal, ""}, states)
+
+	_, err = Read(strings.NewReader(`<xliff version="1.1"/>`))
+	assert.Error(t, err)
+}